@@ -8,12 +8,17 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
+	grpcdelivery "data-cleaner/internal/delivery/grpc"
 	"data-cleaner/internal/delivery/http"
+	"data-cleaner/internal/pkg/auth"
 	"data-cleaner/internal/pkg/config"
 	"data-cleaner/internal/pkg/logger"
-	"data-cleaner/internal/pkg/postgres"
-	repo "data-cleaner/internal/repository/postgres"
+	"data-cleaner/internal/pkg/metrics"
+	"data-cleaner/internal/pkg/tracing"
+	"data-cleaner/internal/pkg/webhook"
+	"data-cleaner/internal/repository"
 	"data-cleaner/internal/usecase"
 )
 
@@ -28,9 +33,13 @@ func main() {
 		panic(err)
 	}
 
-	// Инициализируем логгер
+	// Инициализируем логгер; уровень меняется при перезагрузке конфигурации
 	isDevelopment := os.Getenv("APP_ENV") != "production"
-	l, err := logger.NewLogger(isDevelopment)
+	logLevel, err := zap.ParseAtomicLevel(cfg.LogLevel)
+	if err != nil {
+		panic(err)
+	}
+	l, err := logger.NewLogger(isDevelopment, logLevel)
 	if err != nil {
 		panic(err)
 	}
@@ -38,20 +47,63 @@ func main() {
 
 	log := l.Named("main")
 
+	// Инициализируем трассировку
+	shutdownTracing, err := tracing.NewTracerProvider(ctx, cfg, log.Named("tracing"))
+	if err != nil {
+		log.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+
 	// Подключаемся к базе данных
-	db, err := postgres.NewPostgresDB(ctx, cfg, log)
+	repos, err := repository.Open(ctx, cfg, log)
 	if err != nil {
 		log.Fatal("Failed to connect to database", zap.Error(err))
 	}
-	defer postgres.CloseDB(db, log)
+	defer repos.Close()
+
+	// Инициализируем метрики
+	appMetrics := metrics.NewMetrics()
 
 	// Инициализируем слои приложения
-	cleanerRepo := repo.NewPostgresRepository(db, log.Named("repository"))
-	cleanerUseCase := usecase.NewCleanerUseCase(cleanerRepo, log.Named("usecase"))
-	handler := http.NewHandler(cleanerUseCase, log.Named("handler"))
+	// Создаем диспетчер уведомлений о завершении задач
+	dispatcher := webhook.NewDispatcher(webhook.Config{
+		Targets:       cfg.WebhookURLs,
+		Secret:        []byte(cfg.WebhookSecret),
+		Callbacks:     cfg.WebhookCallbacks,
+		CallbackHosts: cfg.WebhookCallbackHosts,
+		MaxAttempts:   cfg.WebhookMaxAttempts,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    5 * time.Minute,
+		Timeout:       cfg.WebhookTimeout,
+	}, repos.DeadLetters, log.Named("webhook"))
+
+	cleanerUseCase := usecase.NewCleanerUseCase(repos.Cleaner, log.Named("usecase"),
+		usecase.WithSettings(cfg.RuntimeSettings()),
+		usecase.WithMetrics(appMetrics),
+		usecase.WithAuditLog(repos.Audit),
+		usecase.WithCopySwap(repos.CopySwap),
+		usecase.WithNotifier(dispatcher),
+	)
+	healthUseCase := usecase.NewHealthUseCase(repos.Health, cleanerUseCase, log.Named("health"), 2*time.Second)
+	auditUseCase := usecase.NewAuditUseCase(repos.Audit, log.Named("audit"))
+	handler := http.NewHandler(cleanerUseCase, healthUseCase, auditUseCase, log.Named("handler"))
 
 	// Создаем и запускаем HTTP-сервер
-	server := http.NewServer(handler, log.Named("server"), cfg.ServerPort)
+	serverOpts := []http.ServerOption{http.WithMetrics(appMetrics)}
+	var grpcOpts []grpcdelivery.ServerOption
+	if cfg.AuthEnabled {
+		keys, err := auth.LoadKeys(cfg.AuthKeysFile)
+		if err != nil {
+			log.Fatal("Failed to load API keys", zap.Error(err))
+		}
+		log.Info("API authentication enabled", zap.Int("keys", len(keys)))
+		authenticator := auth.NewAuthenticator(keys)
+		serverOpts = append(serverOpts, http.WithAuthenticator(authenticator))
+		grpcOpts = append(grpcOpts, grpcdelivery.WithAuthenticator(authenticator), grpcdelivery.WithAuditLog(auditUseCase))
+	} else {
+		log.Warn("API authentication is disabled")
+	}
+
+	server := http.NewServer(handler, log.Named("server"), cfg.ServerPort, serverOpts...)
 
 	// Запускаем сервер в отдельной горутине
 	go func() {
@@ -60,6 +112,27 @@ func main() {
 		}
 	}()
 
+	// Запускаем gRPC-сервер на отдельном порту
+	var grpcServer *grpcdelivery.Server
+	if cfg.GRPCPort != 0 {
+		grpcHandler := grpcdelivery.NewHandler(cleanerUseCase, log.Named("grpc"))
+		grpcServer = grpcdelivery.NewServer(grpcHandler, log.Named("grpc"), cfg.GRPCPort, grpcOpts...)
+
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				log.Fatal("Failed to start gRPC server", zap.Error(err))
+			}
+		}()
+	}
+
+	// Применяем безопасные настройки без перезапуска по SIGHUP или при изменении файла
+	go config.Watch(ctx, cfg, log.Named("config"), func(next *config.Config) {
+		if level, err := zapcore.ParseLevel(next.LogLevel); err == nil {
+			logLevel.SetLevel(level)
+		}
+		cleanerUseCase.ApplySettings(ctx, next.RuntimeSettings())
+	})
+
 	log.Info("Application started")
 
 	// Обрабатываем сигналы остановки
@@ -78,6 +151,21 @@ func main() {
 	if err := server.Stop(shutdownCtx); err != nil {
 		log.Error("Server shutdown error", zap.Error(err))
 	}
+	if grpcServer != nil {
+		if err := grpcServer.Stop(shutdownCtx); err != nil {
+			log.Error("gRPC server shutdown error", zap.Error(err))
+		}
+	}
+
+	// Дожидаемся отправки уведомлений
+	if err := dispatcher.Close(shutdownCtx); err != nil {
+		log.Error("Webhook dispatcher shutdown error", zap.Error(err))
+	}
+
+	// Выгружаем накопленные спаны
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("Tracing shutdown error", zap.Error(err))
+	}
 
 	log.Info("Application stopped")
 }
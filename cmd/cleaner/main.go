@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/config"
+	"data-cleaner/internal/pkg/logger"
+	"data-cleaner/internal/repository"
+	"data-cleaner/internal/usecase"
+)
+
+// Коды завершения
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitUsage          = 2
+	exitValidation     = 3
+	exitLockBusy       = 4
+	exitPartialFailure = 5
+	exitTimeout        = 6
+	exitLeftovers      = 7
+)
+
+const usage = `Usage: cleaner <command> [flags]
+
+Commands:
+  run          delete rows older than --before from --table
+  dry-run      count rows that would be deleted
+  status       show lock state and pending rows for --table
+  list-tables  list tables with a created_at column
+
+run and dry-run stop after --max-time (default MAX_REQUEST_TIME); --max-time 0 removes the limit.
+
+Exit codes:
+  0 success, 1 error, 2 usage, 3 validation error, 4 table is locked, 5 partial failure,
+  6 batch timed out, 7 completed but rows older than the cutoff remain
+
+Run "cleaner <command> -h" for command flags.
+`
+
+// app содержит зависимости, общие для всех команд
+type app struct {
+	cfg     *config.Config
+	db      *sqlx.DB
+	repo    ports.CleanerRepository
+	cleaner ports.CleanerUseCase
+	log     *zap.Logger
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	commands := map[string]func(context.Context, *app, []string) int{
+		"run":         cmdRun,
+		"dry-run":     cmdDryRun,
+		"status":      cmdStatus,
+		"list-tables": cmdListTables,
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	// Прерываем операцию по Ctrl+C или SIGTERM (например, при остановке Job)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a, cleanup, err := newApp(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+	defer cleanup()
+
+	return command(ctx, a, args[1:])
+}
+
+// newApp загружает конфигурацию и собирает слои приложения так же, как HTTP-сервис
+func newApp(ctx context.Context) (*app, func(), error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// По умолчанию CLI пишет в stderr только предупреждения и ошибки
+	level := zap.NewAtomicLevelAt(zap.WarnLevel)
+	if os.Getenv("CLEANER_VERBOSE") != "" {
+		if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+			return nil, nil, err
+		}
+	}
+	l, err := logger.NewLogger(os.Getenv("APP_ENV") != "production", level)
+	if err != nil {
+		return nil, nil, err
+	}
+	log := l.Named("cli")
+
+	repos, err := repository.Open(ctx, cfg, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	cleanerUseCase := usecase.NewCleanerUseCase(repos.Cleaner, log.Named("usecase"),
+		usecase.WithSettings(cfg.RuntimeSettings()),
+		usecase.WithAuditLog(repos.Audit),
+		usecase.WithCopySwap(repos.CopySwap),
+	)
+
+	cleanup := func() {
+		repos.Close()
+		l.Sync()
+	}
+
+	return &app{
+		cfg:     cfg,
+		db:      repos.DB,
+		repo:    repos.Cleaner,
+		cleaner: cleanerUseCase,
+		log:     log,
+	}, cleanup, nil
+}
+
+// cleanupFlags описывает флаги команд run и dry-run
+type cleanupFlags struct {
+	table       string
+	before      string
+	batchSize   int
+	parallelism int
+	truncate    bool
+	strategy    string
+	output      string
+	maxTime     time.Duration
+	timeouts    struct {
+		statement, lock, idle time.Duration
+	}
+}
+
+func parseCleanupFlags(name string, a *app, args []string) (*cleanupFlags, entities.CleanupRequest, error) {
+	f := &cleanupFlags{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&f.table, "table", "", "table to clean (required)")
+	fs.StringVar(&f.before, "before", "", "delete rows created before this date: RFC3339 or YYYY-MM-DD (required)")
+	fs.IntVar(&f.batchSize, "batch-size", a.cfg.DefaultBatchSize, "rows per batch")
+	fs.IntVar(&f.parallelism, "parallelism", 0, "partitions to clean concurrently (capped by MAX_PARALLELISM)")
+	fs.BoolVar(&f.truncate, "allow-truncate", false, "TRUNCATE the table or partitions whose rows are all older than --before")
+	fs.StringVar(&f.strategy, "strategy", "", "cleanup strategy: batch_delete or copy_swap (PostgreSQL only)")
+	fs.StringVar(&f.output, "output", "table", "output format: table or json")
+	fs.DurationVar(&f.maxTime, "max-time", a.cfg.MaxRequestTime, "limit on the whole cleanup; 0 runs until done or interrupted")
+	fs.DurationVar(&f.timeouts.statement, "statement-timeout", 0, "statement_timeout per batch (default from config)")
+	fs.DurationVar(&f.timeouts.lock, "lock-timeout", 0, "lock_timeout per batch (default from config)")
+	fs.DurationVar(&f.timeouts.idle, "idle-timeout", 0, "idle_in_transaction_session_timeout per batch (default from config)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, entities.CleanupRequest{}, err
+	}
+
+	before, err := parseDate(f.before)
+	if err != nil {
+		return nil, entities.CleanupRequest{}, err
+	}
+
+	return f, entities.CleanupRequest{
+		TableName:     f.table,
+		BeforeDate:    before,
+		BatchSize:     f.batchSize,
+		Parallelism:   f.parallelism,
+		AllowTruncate: f.truncate,
+		Strategy:      f.strategy,
+		Timeouts: entities.BatchTimeouts{
+			Statement:         entities.Duration(f.timeouts.statement),
+			Lock:              entities.Duration(f.timeouts.lock),
+			IdleInTransaction: entities.Duration(f.timeouts.idle),
+		},
+	}, nil
+}
+
+func cmdRun(ctx context.Context, a *app, args []string) int {
+	f, req, err := parseCleanupFlags("run", a, args)
+	if err != nil {
+		return usageError(err)
+	}
+
+	return a.clean(ctx, req, f)
+}
+
+func cmdDryRun(ctx context.Context, a *app, args []string) int {
+	f, req, err := parseCleanupFlags("dry-run", a, args)
+	if err != nil {
+		return usageError(err)
+	}
+	req.DryRun = true
+
+	return a.clean(ctx, req, f)
+}
+
+// clean выполняет очистку через сервис и переводит результат в код завершения
+func (a *app) clean(ctx context.Context, req entities.CleanupRequest, f *cleanupFlags) int {
+	// Сервис ограничивает очистку MAX_REQUEST_TIME; CLI заменяет предел значением --max-time,
+	// так как его не ограничивает таймаут HTTP-ответа
+	settings := a.cfg.RuntimeSettings()
+	settings.MaxRequestTime = f.maxTime
+	a.cleaner.ApplySettings(ctx, settings)
+
+	result, err := a.cleaner.CleanTable(cliContext(ctx), req)
+	if result != nil {
+		if perr := printResult(os.Stdout, f.output, result); perr != nil {
+			return usageError(perr)
+		}
+	}
+
+	return exitCode(result, err)
+}
+
+func cmdStatus(ctx context.Context, a *app, args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	table := fs.String("table", "", "table to inspect (required)")
+	beforeFlag := fs.String("before", "", "count rows created before this date (default: now)")
+	output := fs.String("output", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *table == "" {
+		return usageError(errors.New("-table is required"))
+	}
+
+	before := time.Now()
+	if *beforeFlag != "" {
+		var err error
+		if before, err = parseDate(*beforeFlag); err != nil {
+			return usageError(err)
+		}
+	}
+
+	if err := a.repo.ValidateTable(ctx, *table); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitValidation
+	}
+
+	// Блокировка только проверяется: ее захват помешал бы очистке, запущенной одновременно
+	locked, err := a.repo.IsLocked(ctx, *table)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	pending, err := a.repo.EstimateRows(ctx, *table, before, 1_000_000)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	status := tableStatus{
+		Table:       *table,
+		Locked:      locked,
+		BeforeDate:  before,
+		PendingRows: pending,
+	}
+	if err := printStatus(os.Stdout, *output, status); err != nil {
+		return usageError(err)
+	}
+
+	if status.Locked {
+		return exitLockBusy
+	}
+	return exitOK
+}
+
+func cmdListTables(ctx context.Context, a *app, args []string) int {
+	fs := flag.NewFlagSet("list-tables", flag.ContinueOnError)
+	output := fs.String("output", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	tables, err := a.repo.ListTables(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	if err := printTables(os.Stdout, *output, tables); err != nil {
+		return usageError(err)
+	}
+	return exitOK
+}
+
+// Вспомогательные функции
+
+// cliContext помечает операции CLI пользователем ОС для журнала аудита. Права ключей
+// к CLI не применяются: ему доступны таблицы всех схем, а ограничивает его только
+// политика таблиц из конфигурации.
+func cliContext(ctx context.Context) context.Context {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	return entities.WithPrincipal(ctx, &entities.Principal{
+		ID: "cli:" + name,
+		Scope: entities.AuthScope{
+			Tables: []string{"*.*"},
+			Modes:  []string{entities.ModeSync, entities.ModeDryRun},
+			Admin:  true,
+		},
+	})
+}
+
+// exitCode определяет код завершения по результату и ошибке очистки
+func exitCode(result *entities.CleanupResult, err error) int {
+	if err == nil {
+		if result != nil && result.Status == entities.StatusCompletedWithLeftovers {
+			return exitLeftovers
+		}
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+
+	var domainErr entities.DomainError
+	var forbidden entities.ForbiddenError
+	var policyErr entities.TablePolicyError
+	var notFound entities.NotFoundError
+	var lockErr entities.LockConflictError
+	var timeoutErr entities.TimeoutError
+	switch {
+	case errors.As(err, &lockErr):
+		return exitLockBusy
+	case errors.As(err, &timeoutErr):
+		return exitTimeout
+	case errors.As(err, &domainErr), errors.As(err, &notFound), errors.As(err, &forbidden), errors.As(err, &policyErr):
+		return exitValidation
+	case result != nil && result.RowsDeleted > 0:
+		return exitPartialFailure
+	default:
+		return exitError
+	}
+}
+
+func usageError(err error) int {
+	if !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	return exitUsage
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("-before is required")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use RFC3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/usecase"
+)
+
+// countRepo отвечает на пробный запуск фиксированным числом строк
+type countRepo struct {
+	ports.CleanerRepository
+	rows int
+}
+
+func (r countRepo) ValidateTable(context.Context, string) error { return nil }
+
+func (r countRepo) CountRows(context.Context, string, time.Time) (int, error) {
+	return r.rows, nil
+}
+
+func TestCLIBypassesKeyScopes(t *testing.T) {
+	uc := usecase.NewCleanerUseCase(countRepo{rows: 3}, zap.NewNop())
+
+	tests := []string{"orders", "public.orders", "sales.orders", "Sales.Orders"}
+	for _, table := range tests {
+		t.Run(table, func(t *testing.T) {
+			result, err := uc.CleanTable(cliContext(context.Background()), entities.CleanupRequest{
+				TableName:  table,
+				BeforeDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				DryRun:     true,
+			})
+			if code := exitCode(result, err); code != exitOK {
+				t.Fatalf("exit code = %d (%v), want %d", code, err, exitOK)
+			}
+			if result.RowsMatched != 3 {
+				t.Errorf("rows matched = %d, want 3", result.RowsMatched)
+			}
+		})
+	}
+}
+
+// probeRepo сообщает о занятой блокировке и запоминает попытки ее захвата
+type probeRepo struct {
+	countRepo
+	acquired int
+}
+
+func (r *probeRepo) TryAcquireLock(context.Context, string) (bool, func(), error) {
+	r.acquired++
+	return true, func() {}, nil
+}
+
+func (r *probeRepo) IsLocked(context.Context, string) (bool, error) { return true, nil }
+
+func (r *probeRepo) EstimateRows(context.Context, string, time.Time, int) (int, error) {
+	return r.rows, nil
+}
+
+func TestStatusDoesNotTakeTheLock(t *testing.T) {
+	repo := &probeRepo{countRepo: countRepo{rows: 3}}
+	a := &app{repo: repo, log: zap.NewNop()}
+
+	if code := cmdStatus(context.Background(), a, []string{"-table", "sales.orders", "-output", "json"}); code != exitLockBusy {
+		t.Errorf("exit code = %d, want %d", code, exitLockBusy)
+	}
+	if repo.acquired != 0 {
+		t.Errorf("status acquired the table lock %d times, want 0", repo.acquired)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// tableStatus описывает состояние таблицы для команды status
+type tableStatus struct {
+	Table       string    `json:"table"`
+	Locked      bool      `json:"locked"`
+	BeforeDate  time.Time `json:"before_date"`
+	PendingRows int       `json:"pending_rows"`
+}
+
+func printResult(w io.Writer, format string, result *entities.CleanupResult) error {
+	switch format {
+	case "json":
+		return printJSON(w, result)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "TABLE\t%s\n", result.TableName)
+		fmt.Fprintf(tw, "STATUS\t%s\n", result.Status)
+		if result.Status == entities.StatusDryRun {
+			fmt.Fprintf(tw, "ROWS MATCHED\t%d\n", result.RowsMatched)
+		} else {
+			fmt.Fprintf(tw, "ROWS DELETED\t%d\n", result.RowsDeleted)
+		}
+		if result.RowsCopied > 0 {
+			fmt.Fprintf(tw, "ROWS COPIED\t%d\n", result.RowsCopied)
+		}
+		if result.Strategy != "" {
+			fmt.Fprintf(tw, "STRATEGY\t%s\n", withReason(result.Strategy, result.StrategyReason))
+		}
+		if result.Verified != nil {
+			fmt.Fprintf(tw, "VERIFIED\t%s\n", verification(result.Verified))
+		}
+		fmt.Fprintf(tw, "ELAPSED\t%s\n", result.ElapsedTime.Round(time.Millisecond))
+		if result.Retries > 0 {
+			fmt.Fprintf(tw, "RETRIES\t%d (last: %s)\n", result.Retries, result.LastTransientError)
+		}
+		if result.ErrorMessage != "" {
+			fmt.Fprintf(tw, "ERROR\t%s\n", result.ErrorMessage)
+		}
+		if len(result.Partitions) > 0 {
+			fmt.Fprintln(tw)
+			fmt.Fprintln(tw, "PARTITION\tSTATUS\tROWS DELETED\tELAPSED\tSTRATEGY")
+			for _, p := range result.Partitions {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", p.Name, p.Status, p.RowsDeleted, p.ElapsedTime.Round(time.Millisecond),
+					withReason(p.Strategy, p.StrategyReason))
+			}
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// withReason дополняет способ очистки причиной, по которой не применен TRUNCATE
+func withReason(strategy, reason string) string {
+	if reason == "" {
+		return strategy
+	}
+	return fmt.Sprintf("%s (%s)", strategy, reason)
+}
+
+// verification кратко описывает проверку после очистки
+func verification(v *entities.Verification) string {
+	switch {
+	case v.Error != "":
+		return fmt.Sprintf("not checked (%s)", v.Error)
+	case v.Mismatch:
+		return fmt.Sprintf("mismatch: %d rows remaining, %d deleted, %d estimated", v.RowsRemaining, v.RowsDeleted, v.RowsEstimated)
+	default:
+		return fmt.Sprintf("ok: %d rows remaining", v.RowsRemaining)
+	}
+}
+
+func printStatus(w io.Writer, format string, status tableStatus) error {
+	switch format {
+	case "json":
+		return printJSON(w, status)
+	case "table":
+		lock := "free"
+		if status.Locked {
+			lock = "busy"
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "TABLE\t%s\n", status.Table)
+		fmt.Fprintf(tw, "LOCK\t%s\n", lock)
+		fmt.Fprintf(tw, "PENDING ROWS\t%d (before %s)\n", status.PendingRows, status.BeforeDate.Format(time.RFC3339))
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func printTables(w io.Writer, format string, tables []entities.TableInfo) error {
+	switch format {
+	case "json":
+		return printJSON(w, tables)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tESTIMATED ROWS\tDATE INDEX\tPARTITIONED")
+		for _, t := range tables {
+			fmt.Fprintf(tw, "%s\t%d\t%t\t%t\n", t.Name, t.EstimatedRows, t.HasDateIndex, t.Partitioned)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
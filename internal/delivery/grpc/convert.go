@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	cleanerv1 "data-cleaner/api/cleaner/v1"
+	"data-cleaner/internal/models/entities"
+)
+
+// fromProtoRequest преобразует запрос gRPC в запрос на очистку
+func fromProtoRequest(in *cleanerv1.CleanupRequest) entities.CleanupRequest {
+	req := entities.CleanupRequest{
+		TableName:     in.GetTableName(),
+		BatchSize:     int(in.GetBatchSize()),
+		DryRun:        in.GetDryRun(),
+		Parallelism:   int(in.GetParallelism()),
+		AllowTruncate: in.GetAllowTruncate(),
+		Strategy:      in.GetStrategy(),
+	}
+	if in.GetBeforeDate() != nil {
+		req.BeforeDate = in.GetBeforeDate().AsTime()
+	}
+	if t := in.GetTimeouts(); t != nil {
+		req.Timeouts = entities.BatchTimeouts{
+			Statement:         entities.Duration(t.GetStatement().AsDuration()),
+			Lock:              entities.Duration(t.GetLock().AsDuration()),
+			IdleInTransaction: entities.Duration(t.GetIdleInTransaction().AsDuration()),
+		}
+	}
+	return req
+}
+
+// toProtoResult преобразует результат очистки в сообщение gRPC
+func toProtoResult(r *entities.CleanupResult) *cleanerv1.CleanupResult {
+	if r == nil {
+		return nil
+	}
+
+	partitions := make([]*cleanerv1.PartitionResult, 0, len(r.Partitions))
+	for _, p := range r.Partitions {
+		partitions = append(partitions, &cleanerv1.PartitionResult{
+			Name:           p.Name,
+			Status:         p.Status,
+			RowsDeleted:    int64(p.RowsDeleted),
+			ElapsedTime:    durationpb.New(p.ElapsedTime),
+			ErrorMessage:   p.ErrorMessage,
+			Strategy:       p.Strategy,
+			StrategyReason: p.StrategyReason,
+		})
+	}
+
+	return &cleanerv1.CleanupResult{
+		TableName:          r.TableName,
+		RowsDeleted:        int64(r.RowsDeleted),
+		RowsMatched:        int64(r.RowsMatched),
+		ElapsedTime:        durationpb.New(r.ElapsedTime),
+		Status:             r.Status,
+		ErrorMessage:       r.ErrorMessage,
+		RowsEstimated:      int64(r.RowsEstimated),
+		PercentComplete:    r.PercentComplete,
+		RowsPerSecond:      r.RowsPerSecond,
+		Eta:                durationpb.New(r.ETA),
+		Retries:            int64(r.Retries),
+		LastTransientError: r.LastTransientError,
+		Partitions:         partitions,
+		Strategy:           r.Strategy,
+		StrategyReason:     r.StrategyReason,
+		RowsCopied:         int64(r.RowsCopied),
+		Verified:           toProtoVerification(r.Verified),
+	}
+}
+
+// toProtoVerification преобразует отчет проверки после очистки в сообщение gRPC
+func toProtoVerification(v *entities.Verification) *cleanerv1.Verification {
+	if v == nil {
+		return nil
+	}
+
+	return &cleanerv1.Verification{
+		RowsRemaining: int64(v.RowsRemaining),
+		RowsDeleted:   int64(v.RowsDeleted),
+		RowsEstimated: int64(v.RowsEstimated),
+		Mismatch:      v.Mismatch,
+		CheckedAt:     timestamppb.New(v.CheckedAt),
+		Error:         v.Error,
+	}
+}
+
+// toProtoEvent преобразует событие хода выполнения в сообщение gRPC
+func toProtoEvent(e entities.ProgressEvent) *cleanerv1.ProgressEvent {
+	return &cleanerv1.ProgressEvent{
+		Type:            e.Type,
+		TaskId:          e.TaskID,
+		Time:            timestamppb.New(e.Time),
+		Status:          e.Status,
+		BatchRows:       int64(e.BatchRows),
+		RowsDeleted:     int64(e.RowsDeleted),
+		RowsPerSecond:   e.RowsPerSecond,
+		PercentComplete: e.Percent,
+		Eta:             durationpb.New(e.ETA),
+		Reason:          e.Reason,
+		Result:          toProtoResult(e.Result),
+		Phase:           e.Phase,
+	}
+}
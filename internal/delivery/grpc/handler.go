@@ -0,0 +1,159 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	cleanerv1 "data-cleaner/api/cleaner/v1"
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+)
+
+// Ключ метаданных с ключом идемпотентности асинхронной задачи
+const metadataIdempotencyKey = "idempotency-key"
+
+// Handler реализует gRPC-сервис очистки данных поверх ports.CleanerUseCase
+type Handler struct {
+	cleanerv1.UnimplementedCleanerServiceServer
+
+	cleanerUseCase ports.CleanerUseCase
+	logger         *zap.Logger
+}
+
+// NewHandler создает новый обработчик gRPC-запросов
+func NewHandler(uc ports.CleanerUseCase, logger *zap.Logger) *Handler {
+	return &Handler{
+		cleanerUseCase: uc,
+		logger:         logger,
+	}
+}
+
+// CleanTable выполняет синхронную очистку данных
+func (h *Handler) CleanTable(ctx context.Context, in *cleanerv1.CleanupRequest) (*cleanerv1.CleanupResult, error) {
+	result, err := h.cleanerUseCase.CleanTable(ctx, fromProtoRequest(in))
+	if err != nil {
+		return nil, h.toStatus(err, "Cleanup error")
+	}
+
+	return toProtoResult(result), nil
+}
+
+// StartAsyncCleanup запускает асинхронную очистку данных
+func (h *Handler) StartAsyncCleanup(ctx context.Context, in *cleanerv1.CleanupRequest) (*cleanerv1.StartAsyncCleanupResponse, error) {
+	// Повторный вызов с тем же ключом возвращает исходную задачу
+	if keys := metadata.ValueFromIncomingContext(ctx, metadataIdempotencyKey); len(keys) > 0 {
+		ctx = entities.WithIdempotencyKey(ctx, keys[0])
+	}
+
+	taskID, err := h.cleanerUseCase.StartAsyncCleanup(ctx, fromProtoRequest(in))
+	if err != nil {
+		return nil, h.toStatus(err, "Async cleanup error")
+	}
+
+	return &cleanerv1.StartAsyncCleanupResponse{TaskId: taskID}, nil
+}
+
+// GetCleanupStatus возвращает статус асинхронной задачи
+func (h *Handler) GetCleanupStatus(ctx context.Context, in *cleanerv1.GetCleanupStatusRequest) (*cleanerv1.CleanupResult, error) {
+	result, err := h.cleanerUseCase.GetCleanupStatus(ctx, in.GetTaskId())
+	if err != nil {
+		return nil, h.toStatus(err, "Cleanup status error")
+	}
+
+	return toProtoResult(result), nil
+}
+
+// WatchProgress транслирует события хода выполнения задачи до ее завершения
+func (h *Handler) WatchProgress(in *cleanerv1.WatchProgressRequest, stream grpc.ServerStreamingServer[cleanerv1.ProgressEvent]) error {
+	ctx := stream.Context()
+	taskID := in.GetTaskId()
+
+	events, cancel, err := h.cleanerUseCase.SubscribeProgress(ctx, taskID)
+	if err != nil {
+		return h.toStatus(err, "Watch progress error")
+	}
+	defer cancel()
+
+	// Первым событием отправляем текущее состояние задачи
+	if result, err := h.cleanerUseCase.GetCleanupStatus(ctx, taskID); err == nil {
+		if err := stream.Send(toProtoEvent(entities.ProgressEvent{
+			Type:        entities.EventStatus,
+			TaskID:      taskID,
+			Time:        time.Now().UTC(),
+			Status:      result.Status,
+			RowsDeleted: result.RowsDeleted,
+		})); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// Итоговое событие могло быть пропущено медленным подписчиком
+				if result, err := h.cleanerUseCase.GetCleanupStatus(ctx, taskID); err == nil {
+					return stream.Send(toProtoEvent(entities.ProgressEvent{
+						Type:        entities.EventFinished,
+						TaskID:      taskID,
+						Time:        time.Now().UTC(),
+						Status:      result.Status,
+						RowsDeleted: result.RowsDeleted,
+						Result:      result,
+					}))
+				}
+				return nil
+			}
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+			if event.Type == entities.EventFinished {
+				return nil
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// toStatus преобразует ошибку сервиса в gRPC-статус
+func (h *Handler) toStatus(err error, msg string) error {
+	var forbidden entities.ForbiddenError
+	var policyErr entities.TablePolicyError
+	var lockErr entities.LockConflictError
+	var windowErr entities.WindowClosedError
+	var idempotencyErr entities.IdempotencyConflictError
+	var notFound entities.NotFoundError
+	var timeoutErr entities.TimeoutError
+	var domainErr entities.DomainError
+
+	switch {
+	case errors.As(err, &forbidden), errors.As(err, &policyErr):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &domainErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &lockErr):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.As(err, &windowErr):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &idempotencyErr):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.As(err, &timeoutErr), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	cleanerv1 "data-cleaner/api/cleaner/v1"
+	"data-cleaner/internal/models/entities"
+)
+
+func TestFromProtoRequest(t *testing.T) {
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		in   *cleanerv1.CleanupRequest
+		want entities.CleanupRequest
+	}{
+		{
+			name: "all fields",
+			in: &cleanerv1.CleanupRequest{
+				TableName:  "events",
+				BeforeDate: timestamppb.New(before),
+				BatchSize:  100,
+				DryRun:     true,
+			},
+			want: entities.CleanupRequest{TableName: "events", BeforeDate: before, BatchSize: 100, DryRun: true},
+		},
+		{
+			// Отсутствующая дата остается нулевой и отклоняется проверкой запроса
+			name: "no before date",
+			in:   &cleanerv1.CleanupRequest{TableName: "events", BatchSize: 100},
+			want: entities.CleanupRequest{TableName: "events", BatchSize: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fromProtoRequest(tt.in)
+			if got.TableName != tt.want.TableName || !got.BeforeDate.Equal(tt.want.BeforeDate) ||
+				got.BatchSize != tt.want.BatchSize || got.DryRun != tt.want.DryRun {
+				t.Errorf("fromProtoRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToProtoResult(t *testing.T) {
+	if toProtoResult(nil) != nil {
+		t.Error("toProtoResult(nil) != nil")
+	}
+
+	r := &entities.CleanupResult{
+		TableName:       "events",
+		RowsDeleted:     500,
+		RowsMatched:     700,
+		ElapsedTime:     10 * time.Second,
+		Status:          entities.StatusInProgress,
+		ErrorMessage:    "slow",
+		RowsEstimated:   1000,
+		PercentComplete: 50,
+		RowsPerSecond:   50,
+		ETA:             10 * time.Second,
+	}
+	got := toProtoResult(r)
+
+	if got.GetTableName() != "events" || got.GetRowsDeleted() != 500 || got.GetRowsMatched() != 700 ||
+		got.GetStatus() != entities.StatusInProgress || got.GetErrorMessage() != "slow" ||
+		got.GetRowsEstimated() != 1000 || got.GetPercentComplete() != 50 || got.GetRowsPerSecond() != 50 {
+		t.Errorf("toProtoResult() = %v, want fields of %+v", got, r)
+	}
+	if got.GetElapsedTime().AsDuration() != r.ElapsedTime || got.GetEta().AsDuration() != r.ETA {
+		t.Errorf("durations = %v, %v, want %v, %v", got.GetElapsedTime(), got.GetEta(), r.ElapsedTime, r.ETA)
+	}
+}
+
+func TestToProtoEvent(t *testing.T) {
+	at := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	e := entities.ProgressEvent{
+		Type:        entities.EventFinished,
+		TaskID:      "t-1",
+		Time:        at,
+		Status:      entities.StatusCompleted,
+		RowsDeleted: 1000,
+		Percent:     100,
+		Result:      &entities.CleanupResult{TableName: "events", RowsDeleted: 1000},
+	}
+	got := toProtoEvent(e)
+
+	if got.GetType() != e.Type || got.GetTaskId() != "t-1" || !got.GetTime().AsTime().Equal(at) ||
+		got.GetStatus() != e.Status || got.GetRowsDeleted() != 1000 || got.GetPercentComplete() != 100 {
+		t.Errorf("toProtoEvent() = %v, want fields of %+v", got, e)
+	}
+	if got.GetResult().GetTableName() != "events" {
+		t.Errorf("result = %v, want the result of events", got.GetResult())
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string // Пусто - сообщение ошибки передается клиенту
+	}{
+		{"forbidden", entities.ForbiddenError{Message: "denied"}, codes.PermissionDenied, ""},
+		{"table policy", entities.TablePolicyError{Table: "users", Reason: "protected"}, codes.PermissionDenied, ""},
+		{"invalid request", entities.ErrEmptyTableName, codes.InvalidArgument, ""},
+		{"wrapped invalid request", fmt.Errorf("admit: %w", entities.ErrInvalidBatchSize), codes.InvalidArgument, ""},
+		{"lock conflict", entities.LockConflictError{Table: "events"}, codes.Aborted, ""},
+		{"idempotency conflict", entities.IdempotencyConflictError{Key: "k1"}, codes.AlreadyExists, ""},
+		{"task not found", entities.NotFoundError{Resource: "task", Name: "t-1"}, codes.NotFound, ""},
+		{"window closed", entities.WindowClosedError{Table: "events", NotStarted: true}, codes.FailedPrecondition, ""},
+		{"statement timeout", entities.TimeoutError{Kind: entities.TimeoutStatement, Err: errors.New("canceling statement")}, codes.DeadlineExceeded, ""},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, ""},
+		{"canceled", context.Canceled, codes.Canceled, ""},
+		{"internal error is hidden", errors.New("password=secret"), codes.Internal, "internal server error"},
+	}
+
+	h := NewHandler(nil, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(h.toStatus(tt.err, "test"))
+			if !ok {
+				t.Fatalf("toStatus() did not return a gRPC status")
+			}
+			if st.Code() != tt.wantCode {
+				t.Errorf("code = %v, want %v", st.Code(), tt.wantCode)
+			}
+			wantMessage := tt.wantMessage
+			if wantMessage == "" {
+				wantMessage = tt.err.Error()
+			}
+			if st.Message() != wantMessage {
+				t.Errorf("message = %q, want %q", st.Message(), wantMessage)
+			}
+		})
+	}
+}
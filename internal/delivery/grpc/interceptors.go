@@ -0,0 +1,195 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	cleanerv1 "data-cleaner/api/cleaner/v1"
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/auth"
+)
+
+// Ключ метаданных с идентификатором запроса
+const metadataRequestID = "x-request-id"
+
+// LoggingUnaryInterceptor логирует информацию об унарных вызовах
+func LoggingUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, done := startCall(ctx, logger, info.FullMethod)
+		grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, entities.RequestIDFromContext(ctx)))
+
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// LoggingStreamInterceptor логирует информацию о потоковых вызовах
+func LoggingStreamInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := startCall(ss.Context(), logger, info.FullMethod)
+		ss.SetHeader(metadata.Pairs(metadataRequestID, entities.RequestIDFromContext(ctx)))
+
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		done(err)
+		return err
+	}
+}
+
+// startCall добавляет в контекст идентификатор запроса и логирует начало вызова.
+// Возвращаемая функция логирует завершение вызова.
+func startCall(ctx context.Context, logger *zap.Logger, method string) (context.Context, func(error)) {
+	// Генерируем уникальный ID запроса
+	requestID := uuid.New().String()
+
+	start := time.Now()
+
+	logger.Info("Request started",
+		zap.String("request_id", requestID),
+		zap.String("method", method),
+		zap.String("remote_addr", peerAddr(ctx)))
+
+	return entities.WithRequestID(ctx, requestID), func(err error) {
+		logger.Info("Request completed",
+			zap.String("request_id", requestID),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)))
+	}
+}
+
+// Методы, запускающие очистку, и признак асинхронного режима. Отказы в них
+// фиксируются в журнале аудита.
+var cleanupMethods = map[string]bool{
+	cleanerv1.CleanerService_CleanTable_FullMethodName:        false,
+	cleanerv1.CleanerService_StartAsyncCleanup_FullMethodName: true,
+}
+
+// AuthUnaryInterceptor проверяет учетные данные унарного вызова и добавляет клиента в контекст.
+// Для подписи запроса телом считается детерминированная protobuf-сериализация сообщения,
+// методом - POST, путем - полное имя метода gRPC. Неаутентифицированные вызовы очистки
+// фиксируются в журнале аудита, если он передан.
+func AuthUnaryInterceptor(authenticator *auth.Authenticator, audit ports.AuditUseCase, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authCtx, err := authenticate(ctx, authenticator, logger, info.FullMethod, req)
+		if err != nil {
+			async, isCleanup := cleanupMethods[info.FullMethod]
+			if in, ok := req.(*cleanerv1.CleanupRequest); ok && isCleanup && audit != nil {
+				cleanup := fromProtoRequest(in)
+				audit.RecordRejected(ctx, cleanup, cleanup.Mode(async), err)
+			}
+			return nil, err
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// AuthStreamInterceptor проверяет учетные данные потокового вызова.
+// Проверка выполняется при получении первого сообщения, чтобы подпись покрывала его тело.
+func AuthStreamInterceptor(authenticator *auth.Authenticator, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &authStream{
+			wrappedStream: wrappedStream{ServerStream: ss, ctx: ss.Context()},
+			authenticate: func(ctx context.Context, msg interface{}) (context.Context, error) {
+				return authenticate(ctx, authenticator, logger, info.FullMethod, msg)
+			},
+		})
+	}
+}
+
+// authenticate определяет клиента по метаданным вызова
+func authenticate(ctx context.Context, authenticator *auth.Authenticator, logger *zap.Logger, method string, msg interface{}) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	principal, err := authenticateMetadata(authenticator, md, method, msg)
+	if err != nil {
+		logger.Warn("Authentication failed",
+			zap.String("method", method),
+			zap.String("remote_addr", peerAddr(ctx)),
+			zap.Error(err))
+		return ctx, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	return entities.WithPrincipal(ctx, principal), nil
+}
+
+func authenticateMetadata(authenticator *auth.Authenticator, md metadata.MD, method string, msg interface{}) (*entities.Principal, error) {
+	if signature := firstValue(md, auth.HeaderSignature); signature != "" {
+		var body []byte
+		if m, ok := msg.(proto.Message); ok {
+			var err error
+			if body, err = (proto.MarshalOptions{Deterministic: true}).Marshal(m); err != nil {
+				return nil, auth.ErrInvalidSignature
+			}
+		}
+		return authenticator.VerifySignature(
+			firstValue(md, auth.HeaderKeyID),
+			firstValue(md, auth.HeaderTimestamp),
+			firstValue(md, auth.HeaderNonce),
+			signature, "POST", method, "", body)
+	}
+
+	key := firstValue(md, auth.HeaderAPIKey)
+	if key == "" {
+		if bearer, ok := strings.CutPrefix(firstValue(md, "authorization"), "Bearer "); ok {
+			key = bearer
+		}
+	}
+	if key == "" {
+		return nil, auth.ErrMissingCredentials
+	}
+
+	return authenticator.AuthenticateKey(key)
+}
+
+// wrappedStream подменяет контекст потока
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authStream проверяет учетные данные при получении первого сообщения потока
+type authStream struct {
+	wrappedStream
+	authenticate  func(context.Context, interface{}) (context.Context, error)
+	authenticated bool
+}
+
+func (s *authStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authenticated {
+		return nil
+	}
+
+	ctx, err := s.authenticate(s.ctx, m)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	s.authenticated = true
+	return nil
+}
+
+// Вспомогательные функции
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	cleanerv1 "data-cleaner/api/cleaner/v1"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/auth"
+)
+
+// Server представляет gRPC-сервер
+type Server struct {
+	grpcServer *grpc.Server
+	addr       string
+	logger     *zap.Logger
+}
+
+// ServerOption настраивает gRPC-сервер
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	authenticator *auth.Authenticator
+	audit         ports.AuditUseCase
+}
+
+// WithAuthenticator включает аутентификацию вызовов
+func WithAuthenticator(a *auth.Authenticator) ServerOption {
+	return func(o *serverOptions) {
+		o.authenticator = a
+	}
+}
+
+// WithAuditLog включает запись в журнал аудита вызовов очистки, отклоненных при аутентификации
+func WithAuditLog(audit ports.AuditUseCase) ServerOption {
+	return func(o *serverOptions) {
+		o.audit = audit
+	}
+}
+
+// NewServer создает новый gRPC-сервер
+func NewServer(handler *Handler, logger *zap.Logger, port int, opts ...ServerOption) *Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Регистрируем перехватчики в том же порядке, что и HTTP middleware
+	unary := []grpc.UnaryServerInterceptor{LoggingUnaryInterceptor(logger)}
+	stream := []grpc.StreamServerInterceptor{LoggingStreamInterceptor(logger)}
+	if options.authenticator != nil {
+		unary = append(unary, AuthUnaryInterceptor(options.authenticator, options.audit, logger))
+		stream = append(stream, AuthStreamInterceptor(options.authenticator, logger))
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	cleanerv1.RegisterCleanerServiceServer(grpcServer, handler)
+
+	return &Server{
+		grpcServer: grpcServer,
+		addr:       fmt.Sprintf(":%d", port),
+		logger:     logger,
+	}
+}
+
+// Start запускает gRPC-сервер
+func (s *Server) Start() error {
+	s.logger.Info("Starting gRPC server", zap.String("address", s.addr))
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	if err := s.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+
+	return nil
+}
+
+// Stop останавливает gRPC-сервер, дожидаясь завершения вызовов до истечения контекста
+func (s *Server) Stop(ctx context.Context) error {
+	s.logger.Info("Shutting down gRPC server")
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// Прерываем оставшиеся вызовы, в том числе открытые потоки событий
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// peerAddr возвращает адрес клиента вызова
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
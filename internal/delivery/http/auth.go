@@ -0,0 +1,79 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/auth"
+)
+
+// Пути, доступные без аутентификации
+var publicPaths = map[string]bool{
+	"/livez":               true,
+	"/readyz":              true,
+	"/metrics":             true,
+	"/api/v1/health":       true,
+	"/api/v1/openapi.json": true,
+}
+
+// Маршруты, запускающие очистку, и признак асинхронного режима. Отказы в них
+// фиксируются в журнале аудита.
+var cleanupPaths = map[string]bool{
+	"/api/v1/cleanup":       false,
+	"/api/v1/cleanup/async": true,
+}
+
+// AuthMiddleware проверяет учетные данные запроса и добавляет клиента в контекст.
+// Неаутентифицированные запросы на очистку фиксируются в журнале аудита.
+func AuthMiddleware(authenticator *auth.Authenticator, audit ports.AuditUseCase, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if publicPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				logger.Warn("Authentication failed",
+					zap.String("path", r.URL.Path),
+					zap.String("remote_addr", r.RemoteAddr),
+					zap.Error(err))
+				recordRejected(r, audit, err)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="data-cleaner"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(newErrorResponse(r, codeUnauthorized, "Unauthorized", nil))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(entities.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// recordRejected фиксирует в журнале аудита отклоненный запрос на очистку. Таблица и режим
+// берутся из тела запроса, если его удается разобрать: отказ фиксируется и для
+// некорректного тела. Запросы к другим маршрутам не фиксируются.
+func recordRejected(r *http.Request, audit ports.AuditUseCase, reason error) {
+	async, ok := cleanupPaths[r.URL.Path]
+	if !ok || r.Method != http.MethodPost || audit == nil {
+		return
+	}
+
+	var req entities.CleanupRequest
+	if body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize)); err == nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		// Ошибка разбора не мешает записи: сохраняются поля, которые удалось прочитать
+		_ = json.Unmarshal(body, &req)
+	}
+
+	audit.RecordRejected(r.Context(), req, req.Mode(async), reason)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/pkg/auth"
+
+	"go.uber.org/zap"
+)
+
+// fakeAudit запоминает отклоненные запросы
+type fakeAudit struct {
+	mu       sync.Mutex
+	rejected []rejectedRequest
+}
+
+type rejectedRequest struct {
+	req    entities.CleanupRequest
+	mode   string
+	reason string
+}
+
+func (a *fakeAudit) ListEntries(context.Context, entities.AuditFilter) ([]entities.AuditEntry, error) {
+	return nil, nil
+}
+
+func (a *fakeAudit) Verify(context.Context) (*entities.AuditVerification, error) {
+	return &entities.AuditVerification{Valid: true}, nil
+}
+
+func (a *fakeAudit) RecordRejected(_ context.Context, req entities.CleanupRequest, mode string, reason error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rejected = append(a.rejected, rejectedRequest{req: req, mode: mode, reason: reason.Error()})
+}
+
+func TestAuthMiddlewareAuditsRejectedCleanups(t *testing.T) {
+	hash := sha256.Sum256([]byte("secret"))
+	authenticator := auth.NewAuthenticator([]auth.APIKey{{
+		ID:      "ops",
+		KeyHash: hash[:],
+		Scope:   entities.AuthScope{Tables: []string{"*"}, Modes: []string{entities.ModeSync}},
+	}})
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		key       string
+		wantCode  int
+		wantAudit *rejectedRequest
+	}{
+		{
+			name:     "sync cleanup without credentials",
+			method:   http.MethodPost,
+			path:     "/api/v1/cleanup",
+			body:     `{"table_name":"users","before_date":"2024-01-01T00:00:00Z"}`,
+			wantCode: http.StatusUnauthorized,
+			wantAudit: &rejectedRequest{
+				req:  entities.CleanupRequest{TableName: "users"},
+				mode: entities.ModeSync,
+			},
+		},
+		{
+			name:     "async dry run with a wrong key",
+			method:   http.MethodPost,
+			path:     "/api/v1/cleanup/async",
+			body:     `{"table_name":"orders","dry_run":true}`,
+			key:      "wrong",
+			wantCode: http.StatusUnauthorized,
+			wantAudit: &rejectedRequest{
+				req:  entities.CleanupRequest{TableName: "orders", DryRun: true},
+				mode: entities.ModeDryRun,
+			},
+		},
+		{
+			name:     "malformed body is still audited",
+			method:   http.MethodPost,
+			path:     "/api/v1/cleanup",
+			body:     `{"table_name":`,
+			wantCode: http.StatusUnauthorized,
+			wantAudit: &rejectedRequest{
+				mode: entities.ModeSync,
+			},
+		},
+		{
+			name:     "status request is not audited",
+			method:   http.MethodGet,
+			path:     "/api/v1/cleanup/123",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "authenticated request passes through",
+			method:   http.MethodPost,
+			path:     "/api/v1/cleanup",
+			body:     `{"table_name":"users"}`,
+			key:      "secret",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAudit{}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := AuthMiddleware(authenticator, audit, zap.NewNop())(next)
+
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.key != "" {
+				r.Header.Set(auth.HeaderAPIKey, tt.key)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantAudit == nil {
+				if len(audit.rejected) != 0 {
+					t.Fatalf("audited %+v, want nothing", audit.rejected)
+				}
+				return
+			}
+			if len(audit.rejected) != 1 {
+				t.Fatalf("audited %d requests, want 1", len(audit.rejected))
+			}
+			got := audit.rejected[0]
+			if got.req.TableName != tt.wantAudit.req.TableName || got.req.DryRun != tt.wantAudit.req.DryRun || got.mode != tt.wantAudit.mode {
+				t.Errorf("audited %+v, want %+v", got, *tt.wantAudit)
+			}
+			if got.reason == "" {
+				t.Error("rejection reason is empty")
+			}
+		})
+	}
+}
+
+func TestHandleCleanupAuditsInvalidBody(t *testing.T) {
+	audit := &fakeAudit{}
+	h := NewHandler(nil, nil, audit, zap.NewNop())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/cleanup",
+		strings.NewReader(`{"table_name":"users","batch_size":"many"}`))
+	w := httptest.NewRecorder()
+	h.HandleCleanup(w, r)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if len(audit.rejected) != 1 || audit.rejected[0].req.TableName != "users" || audit.rejected[0].mode != entities.ModeSync {
+		t.Fatalf("audited %+v, want one sync rejection for users", audit.rejected)
+	}
+}
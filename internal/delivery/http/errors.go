@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// Машиночитаемые коды ошибок API
+const (
+	codeValidationFailed    = "validation_failed"
+	codeUnauthorized        = "unauthorized"
+	codeForbidden           = "forbidden"
+	codeTableNotAllowed     = "table_not_allowed"
+	codeNotFound            = "not_found"
+	codeLockConflict        = "lock_conflict"
+	codeWindowClosed        = "window_closed"
+	codeIdempotencyConflict = "idempotency_conflict"
+	codeTimeout             = "timeout"
+	codeInternal            = "internal_error"
+)
+
+// lockRetryAfter подсказывает клиенту, когда повторить запрос к таблице, которую уже очищают
+const lockRetryAfter = 30 * time.Second
+
+// errorResponse - единый формат ответа с ошибкой
+type errorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+func newErrorResponse(r *http.Request, code, message string, details interface{}) errorResponse {
+	return errorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: entities.RequestIDFromContext(r.Context()),
+	}
+}
+
+func (h *Handler) respondWithError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	h.respondWithJSON(w, status, newErrorResponse(r, code, message, nil))
+}
+
+// respondWithServiceError отвечает ошибкой сервиса со статусом и кодом, соответствующими ее типу.
+// Неизвестные ошибки записываются в лог и не раскрываются клиенту.
+func (h *Handler) respondWithServiceError(w http.ResponseWriter, r *http.Request, err error, logMsg string) {
+	var validationErr ValidationError
+	var forbidden entities.ForbiddenError
+	var policyErr entities.TablePolicyError
+	var notFound entities.NotFoundError
+	var lockErr entities.LockConflictError
+	var windowErr entities.WindowClosedError
+	var idempotencyErr entities.IdempotencyConflictError
+	var timeoutErr entities.TimeoutError
+	var domainErr entities.DomainError
+
+	switch {
+	case errors.As(err, &validationErr):
+		h.respondWithJSON(w, http.StatusUnprocessableEntity,
+			newErrorResponse(r, codeValidationFailed, "Invalid request", validationErr.Details))
+	case errors.As(err, &forbidden):
+		h.respondWithError(w, r, http.StatusForbidden, codeForbidden, err.Error())
+	case errors.As(err, &policyErr):
+		h.respondWithError(w, r, http.StatusForbidden, codeTableNotAllowed, err.Error())
+	case errors.As(err, &notFound):
+		h.respondWithJSON(w, http.StatusNotFound, newErrorResponse(r, codeNotFound, notFound.Error(),
+			map[string]string{"resource": notFound.Resource, "name": notFound.Name}))
+	case errors.As(err, &lockErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(lockRetryAfter.Seconds())))
+		h.respondWithJSON(w, http.StatusConflict, newErrorResponse(r, codeLockConflict, lockErr.Error(),
+			map[string]string{"table": lockErr.Table}))
+	case errors.As(err, &windowErr):
+		details := map[string]string{"table": windowErr.Table, "rows_deleted": strconv.Itoa(windowErr.RowsDeleted)}
+		if !windowErr.NextOpen.IsZero() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(windowErr.NextOpen).Seconds())+1))
+			details["next_open"] = windowErr.NextOpen.UTC().Format(time.RFC3339)
+		}
+		h.respondWithJSON(w, http.StatusConflict, newErrorResponse(r, codeWindowClosed, windowErr.Error(), details))
+	case errors.As(err, &idempotencyErr):
+		h.respondWithError(w, r, http.StatusConflict, codeIdempotencyConflict, err.Error())
+	case errors.As(err, &domainErr):
+		h.respondWithError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, err.Error())
+	case errors.As(err, &timeoutErr):
+		h.respondWithJSON(w, http.StatusGatewayTimeout, newErrorResponse(r, codeTimeout, err.Error(),
+			map[string]string{"kind": timeoutErr.Kind}))
+	case errors.Is(err, context.DeadlineExceeded):
+		h.respondWithError(w, r, http.StatusGatewayTimeout, codeTimeout, "Request time limit exceeded")
+	default:
+		h.logger.Error(logMsg, zap.Error(err))
+		h.respondWithError(w, r, http.StatusInternalServerError, codeInternal, "Internal server error")
+	}
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+func TestRespondWithServiceError(t *testing.T) {
+	nextOpen := time.Now().Add(2 * time.Hour)
+
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       string
+		wantRetryAfter bool
+		wantNextOpen   bool
+	}{
+		{
+			name:           "request outside the maintenance window",
+			err:            entities.WindowClosedError{Table: "events", NextOpen: nextOpen, NotStarted: true},
+			wantStatus:     http.StatusConflict,
+			wantCode:       codeWindowClosed,
+			wantRetryAfter: true,
+			wantNextOpen:   true,
+		},
+		{
+			name:           "window closed during the run",
+			err:            entities.WindowClosedError{Table: "events", NextOpen: nextOpen, RowsDeleted: 10},
+			wantStatus:     http.StatusConflict,
+			wantCode:       codeWindowClosed,
+			wantRetryAfter: true,
+			wantNextOpen:   true,
+		},
+		{
+			name:       "window without a next opening",
+			err:        entities.WindowClosedError{Table: "events", NotStarted: true},
+			wantStatus: http.StatusConflict,
+			wantCode:   codeWindowClosed,
+		},
+		{
+			name:           "table locked by another cleanup",
+			err:            entities.LockConflictError{Table: "events"},
+			wantStatus:     http.StatusConflict,
+			wantCode:       codeLockConflict,
+			wantRetryAfter: true,
+		},
+		{
+			name:       "statement timeout",
+			err:        fmt.Errorf("batch deletion failed: %w", entities.TimeoutError{Kind: entities.TimeoutStatement, Err: errors.New("canceling statement")}),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   codeTimeout,
+		},
+		{
+			name: "lock timeout after retries",
+			err: fmt.Errorf("giving up after 3 retries: %w", entities.TransientError{Code: "55P03",
+				Err: entities.TimeoutError{Kind: entities.TimeoutLock, Err: errors.New("canceling statement")}}),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   codeTimeout,
+		},
+		{
+			name:       "request time limit",
+			err:        context.DeadlineExceeded,
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   codeTimeout,
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeInternal,
+		},
+	}
+
+	h := NewHandler(nil, nil, &fakeAudit{}, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.respondWithServiceError(w, httptest.NewRequest("POST", "/api/v1/cleanup", nil), tt.err, "cleanup failed")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var resp struct {
+				Code    string            `json:"code"`
+				Details map[string]string `json:"details"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+
+			retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+			if tt.wantRetryAfter && (err != nil || retryAfter <= 0) {
+				t.Errorf("Retry-After = %q, want a positive number of seconds", w.Header().Get("Retry-After"))
+			}
+			if !tt.wantRetryAfter && w.Header().Get("Retry-After") != "" {
+				t.Errorf("Retry-After = %q, want none", w.Header().Get("Retry-After"))
+			}
+			if _, ok := resp.Details["next_open"]; ok != tt.wantNextOpen {
+				t.Errorf("details = %v, want next_open %v", resp.Details, tt.wantNextOpen)
+			}
+		})
+	}
+}
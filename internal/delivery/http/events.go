@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// Интервал отправки комментариев, поддерживающих соединение открытым
+const sseHeartbeatInterval = 15 * time.Second
+
+// HandleCleanupEvents транслирует события хода выполнения задачи как Server-Sent Events
+func (h *Handler) HandleCleanupEvents(w http.ResponseWriter, r *http.Request) {
+	taskID := mux.Vars(r)["taskID"]
+
+	events, cancel, err := h.cleanerUseCase.SubscribeProgress(r.Context(), taskID)
+	if err != nil {
+		h.respondWithServiceError(w, r, err, "Cleanup events error")
+		return
+	}
+	defer cancel()
+
+	// Поток живет дольше таймаута записи сервера
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to disable write deadline for event stream", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Первым событием отправляем текущее состояние задачи
+	if status, err := h.cleanerUseCase.GetCleanupStatus(r.Context(), taskID); err == nil {
+		h.writeEvent(w, rc, entities.ProgressEvent{
+			Type:        entities.EventStatus,
+			TaskID:      taskID,
+			Time:        time.Now().UTC(),
+			Status:      status.Status,
+			RowsDeleted: status.RowsDeleted,
+		})
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// Итоговое событие могло быть пропущено медленным подписчиком
+				if status, err := h.cleanerUseCase.GetCleanupStatus(r.Context(), taskID); err == nil {
+					h.writeEvent(w, rc, entities.ProgressEvent{
+						Type:        entities.EventFinished,
+						TaskID:      taskID,
+						Time:        time.Now().UTC(),
+						Status:      status.Status,
+						RowsDeleted: status.RowsDeleted,
+						Result:      status,
+					})
+				}
+				return
+			}
+			if err := h.writeEvent(w, rc, event); err != nil {
+				return
+			}
+			if event.Type == entities.EventFinished {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			rc.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvent отправляет одно событие в формате text/event-stream
+func (h *Handler) writeEvent(w http.ResponseWriter, rc *http.ResponseController, event entities.ProgressEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Error encoding event", zap.Error(err))
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
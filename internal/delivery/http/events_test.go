@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// streamCleaner отдает заранее записанные события задачи
+type streamCleaner struct {
+	ports.CleanerUseCase
+	events []entities.ProgressEvent
+	close  bool // Закрыть канал после событий, как при пропущенном итоговом событии
+}
+
+func (c streamCleaner) SubscribeProgress(_ context.Context, taskID string) (<-chan entities.ProgressEvent, func(), error) {
+	ch := make(chan entities.ProgressEvent, len(c.events))
+	for _, event := range c.events {
+		ch <- event
+	}
+	if c.close {
+		close(ch)
+	}
+	return ch, func() {}, nil
+}
+
+func (c streamCleaner) GetCleanupStatus(_ context.Context, taskID string) (*entities.CleanupResult, error) {
+	return &entities.CleanupResult{Status: entities.StatusCompleted, RowsDeleted: 30}, nil
+}
+
+func TestHandleCleanupEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		cleaner   streamCleaner
+		wantTypes []string
+	}{
+		{
+			name: "status first, then events until finish",
+			cleaner: streamCleaner{events: []entities.ProgressEvent{
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 10},
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 30},
+				{Type: entities.EventFinished, TaskID: "t-1", Status: entities.StatusCompleted},
+				// После итогового события поток закрыт
+				{Type: entities.EventBatchCompleted, TaskID: "t-1"},
+			}},
+			wantTypes: []string{entities.EventStatus, entities.EventBatchCompleted, entities.EventBatchCompleted, entities.EventFinished},
+		},
+		{
+			name: "closed channel without finish sends the final status",
+			cleaner: streamCleaner{events: []entities.ProgressEvent{
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 10},
+			}, close: true},
+			wantTypes: []string{entities.EventStatus, entities.EventBatchCompleted, entities.EventFinished},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(tt.cleaner, nil, &fakeAudit{}, zap.NewNop())
+			r := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/cleanup/t-1/events", nil),
+				map[string]string{"taskID": "t-1"})
+			w := httptest.NewRecorder()
+
+			// Обработчик возвращается сам, когда поток завершен
+			h.HandleCleanupEvents(w, r)
+
+			if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", ct)
+			}
+			var got []string
+			scanner := bufio.NewScanner(w.Body)
+			for scanner.Scan() {
+				if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+					got = append(got, name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("events = %v, want %v", got, tt.wantTypes)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package http
 
 import (
-	"context"
 	"data-cleaner/internal/models/entities"
 	"data-cleaner/internal/models/ports"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -14,13 +14,17 @@ import (
 
 type Handler struct {
 	cleanerUseCase ports.CleanerUseCase
+	healthUseCase  ports.HealthUseCase
+	auditUseCase   ports.AuditUseCase
 	logger         *zap.Logger
 }
 
 // NewHandler создает новый обработчик HTTP-запросов
-func NewHandler(uc ports.CleanerUseCase, logger *zap.Logger) *Handler {
+func NewHandler(uc ports.CleanerUseCase, health ports.HealthUseCase, audit ports.AuditUseCase, logger *zap.Logger) *Handler {
 	return &Handler{
 		cleanerUseCase: uc,
+		healthUseCase:  health,
+		auditUseCase:   audit,
 		logger:         logger,
 	}
 }
@@ -30,37 +34,40 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/api/v1/cleanup", h.HandleCleanup).Methods(http.MethodPost)
 	r.HandleFunc("/api/v1/cleanup/async", h.HandleAsyncCleanup).Methods(http.MethodPost)
 	r.HandleFunc("/api/v1/cleanup/{taskID}", h.HandleGetCleanupStatus).Methods(http.MethodGet)
-	r.HandleFunc("/api/v1/health", h.HandleHealthCheck).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/cleanup/{taskID}/events", h.HandleCleanupEvents).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/audit", h.HandleListAudit).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/audit/verify", h.HandleVerifyAudit).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/health", h.HandleReadiness).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/openapi.json", h.HandleOpenAPI).Methods(http.MethodGet)
+	r.HandleFunc("/livez", h.HandleLiveness).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", h.HandleReadiness).Methods(http.MethodGet)
 }
 
 // HandleCleanup обрабатывает синхронный запрос на очистку данных
 func (h *Handler) HandleCleanup(w http.ResponseWriter, r *http.Request) {
 	var req entities.CleanupRequest
 
-	// Декодируем JSON-запрос
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+	// Проверяем запрос по спецификации OpenAPI и декодируем его
+	if err := decodeRequest(r, &req); err != nil {
+		recordRejected(r, h.auditUseCase, err)
+		h.respondWithServiceError(w, r, err, "Invalid request")
 		return
 	}
 
-	// Устанавливаем значения по умолчанию, если необходимо
-	if req.BatchSize == 0 {
-		req.BatchSize = 5000 // Значение по умолчанию
+	// Очистка может длиться дольше таймаута записи сервера: ее время ограничивает сервис
+	// (MAX_REQUEST_TIME), а на запись ответа отводится обычный таймаут
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to disable write deadline for synchronous cleanup", zap.Error(err))
 	}
 
-	// Создаем контекст с таймаутом
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
-	defer cancel()
-
-	// Выполняем очистку
-	result, err := h.cleanerUseCase.CleanTable(ctx, req)
+	// Выполняем очистку; размер пакета по умолчанию и предельное время задает сервис
+	result, err := h.cleanerUseCase.CleanTable(r.Context(), req)
+	if err := rc.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		h.logger.Warn("Failed to restore write deadline", zap.Error(err))
+	}
 	if err != nil {
-		if _, ok := err.(entities.DomainError); ok {
-			h.respondWithError(w, http.StatusBadRequest, err.Error())
-		} else {
-			h.logger.Error("Cleanup error", zap.Error(err))
-			h.respondWithError(w, http.StatusInternalServerError, "Internal server error")
-		}
+		h.respondWithServiceError(w, r, err, "Cleanup error")
 		return
 	}
 
@@ -71,32 +78,35 @@ func (h *Handler) HandleCleanup(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) HandleAsyncCleanup(w http.ResponseWriter, r *http.Request) {
 	var req entities.CleanupRequest
 
-	// Декодируем JSON-запрос
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+	// Проверяем запрос по спецификации OpenAPI и декодируем его
+	if err := decodeRequest(r, &req); err != nil {
+		recordRejected(r, h.auditUseCase, err)
+		h.respondWithServiceError(w, r, err, "Invalid request")
 		return
 	}
 
-	// Устанавливаем значения по умолчанию, если необходимо
-	if req.BatchSize == 0 {
-		req.BatchSize = 5000 // Значение по умолчанию
+	// Повторная отправка с тем же ключом возвращает исходную задачу
+	ctx := r.Context()
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		ctx = entities.WithIdempotencyKey(ctx, key)
 	}
 
 	// Запускаем асинхронную очистку
-	taskID, err := h.cleanerUseCase.StartAsyncCleanup(r.Context(), req)
+	taskID, err := h.cleanerUseCase.StartAsyncCleanup(ctx, req)
 	if err != nil {
-		if _, ok := err.(entities.DomainError); ok {
-			h.respondWithError(w, http.StatusBadRequest, err.Error())
-		} else {
-			h.logger.Error("Async cleanup error", zap.Error(err))
-			h.respondWithError(w, http.StatusInternalServerError, "Internal server error")
-		}
+		h.respondWithServiceError(w, r, err, "Async cleanup error")
 		return
 	}
 
+	// Для повторной отправки статус отражает текущее состояние исходной задачи
+	status := entities.StatusPending
+	if result, err := h.cleanerUseCase.GetCleanupStatus(ctx, taskID); err == nil {
+		status = result.Status
+	}
+
 	h.respondWithJSON(w, http.StatusAccepted, map[string]string{
 		"task_id":    taskID,
-		"status":     "pending",
+		"status":     status,
 		"status_url": "/api/v1/cleanup/" + taskID,
 	})
 }
@@ -110,27 +120,81 @@ func (h *Handler) HandleGetCleanupStatus(w http.ResponseWriter, r *http.Request)
 	// Получаем статус
 	result, err := h.cleanerUseCase.GetCleanupStatus(r.Context(), taskID)
 	if err != nil {
-		h.respondWithError(w, http.StatusNotFound, "Task not found")
+		h.respondWithServiceError(w, r, err, "Cleanup status error")
 		return
 	}
 
 	h.respondWithJSON(w, http.StatusOK, result)
 }
 
-// HandleHealthCheck проверяет работоспособность сервиса
-func (h *Handler) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	h.respondWithJSON(w, http.StatusOK, map[string]string{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
+// HandleListAudit возвращает записи журнала аудита
+func (h *Handler) HandleListAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := entities.AuditFilter{TableName: query.Get("table")}
+
+	if v := query.Get("after_seq"); v != "" {
+		seq, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			h.respondWithError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "Invalid after_seq")
+			return
+		}
+		filter.AfterSeq = seq
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			h.respondWithError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.auditUseCase.ListEntries(r.Context(), filter)
+	if err != nil {
+		h.respondWithServiceError(w, r, err, "Audit list error")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
 	})
 }
 
-// Вспомогательные функции для ответов
+// HandleVerifyAudit проверяет целостность цепочки журнала аудита
+func (h *Handler) HandleVerifyAudit(w http.ResponseWriter, r *http.Request) {
+	result, err := h.auditUseCase.Verify(r.Context())
+	if err != nil {
+		h.respondWithServiceError(w, r, err, "Audit verification error")
+		return
+	}
+
+	code := http.StatusOK
+	if !result.Valid {
+		code = http.StatusConflict
+	}
+
+	h.respondWithJSON(w, code, result)
+}
 
-func (h *Handler) respondWithError(w http.ResponseWriter, code int, message string) {
-	h.respondWithJSON(w, code, map[string]string{"error": message})
+// HandleLiveness сообщает, что процесс жив
+func (h *Handler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, h.healthUseCase.Liveness(r.Context()))
 }
 
+// HandleReadiness проверяет готовность сервиса обслуживать запросы
+func (h *Handler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := h.healthUseCase.Readiness(r.Context())
+
+	code := http.StatusOK
+	if report.Status == entities.HealthStatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	h.respondWithJSON(w, code, report)
+}
+
+// Вспомогательные функции для ответов
+
 func (h *Handler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	// Устанавливаем заголовок Content-Type
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"go.uber.org/zap"
+)
+
+// slowCleaner выполняет синхронную очистку дольше таймаута записи сервера
+type slowCleaner struct {
+	ports.CleanerUseCase
+	delay time.Duration
+}
+
+func (c slowCleaner) CleanTable(ctx context.Context, req entities.CleanupRequest) (*entities.CleanupResult, error) {
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &entities.CleanupResult{TableName: req.TableName, RowsDeleted: 42, Status: entities.StatusCompleted}, nil
+}
+
+func TestHandleCleanupOutlivesServerWriteTimeout(t *testing.T) {
+	h := NewHandler(slowCleaner{delay: 300 * time.Millisecond}, nil, &fakeAudit{}, zap.NewNop())
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(h.HandleCleanup))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/v1/cleanup", "application/json",
+		strings.NewReader(`{"table_name":"users","before_date":"2024-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var result entities.CleanupResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if result.RowsDeleted != 42 {
+		t.Errorf("rows_deleted = %d, want 42", result.RowsDeleted)
+	}
+}
+
+// keyedCleaner запоминает отпечаток первого запроса для каждого ключа идемпотентности
+type keyedCleaner struct {
+	ports.CleanerUseCase
+	tables map[string]string
+}
+
+func (c *keyedCleaner) StartAsyncCleanup(ctx context.Context, req entities.CleanupRequest) (string, error) {
+	key := entities.IdempotencyKeyFromContext(ctx)
+	if table, ok := c.tables[key]; ok && table != req.TableName {
+		return "", entities.IdempotencyConflictError{Key: key}
+	}
+	c.tables[key] = req.TableName
+	return "task-" + key, nil
+}
+
+func (c *keyedCleaner) GetCleanupStatus(context.Context, string) (*entities.CleanupResult, error) {
+	return &entities.CleanupResult{Status: entities.StatusInProgress}, nil
+}
+
+func TestHandleAsyncCleanupIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		table      string
+		wantStatus int
+		wantTask   string
+	}{
+		{"first submission", "users", http.StatusAccepted, "task-k1"},
+		{"same request again", "users", http.StatusAccepted, "task-k1"},
+		{"different request with the same key", "orders", http.StatusConflict, ""},
+	}
+
+	h := NewHandler(&keyedCleaner{tables: map[string]string{}}, nil, &fakeAudit{}, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/cleanup/async",
+				strings.NewReader(`{"table_name":"`+tt.table+`","before_date":"2024-01-01T00:00:00Z"}`))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Idempotency-Key", "k1")
+			w := httptest.NewRecorder()
+
+			h.HandleAsyncCleanup(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var resp map[string]interface{}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if tt.wantTask != "" && resp["task_id"] != tt.wantTask {
+				t.Errorf("task_id = %v, want %s", resp["task_id"], tt.wantTask)
+			}
+		})
+	}
+}
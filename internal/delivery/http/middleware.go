@@ -5,7 +5,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/pkg/metrics"
 )
 
 // LoggingMiddleware логирует информацию о HTTP-запросах
@@ -32,8 +41,8 @@ func LoggingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 				zap.String("remote_addr", r.RemoteAddr),
 				zap.String("user_agent", r.UserAgent()))
 
-			// Вызываем следующий обработчик
-			next.ServeHTTP(rw, r)
+			// Вызываем следующий обработчик, передавая ID запроса через контекст
+			next.ServeHTTP(rw, r.WithContext(entities.WithRequestID(r.Context(), requestID)))
 
 			// Логируем информацию о завершении запроса
 			logger.Info("Request completed",
@@ -45,6 +54,64 @@ func LoggingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// MetricsMiddleware собирает метрики HTTP-запросов по статусу и размеру ответа
+func MetricsMiddleware(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Оборачиваем ResponseWriter для отслеживания статуса ответа
+			rw := newResponseWriter(w)
+			start := time.Now()
+
+			next.ServeHTTP(rw, r)
+
+			m.ObserveHTTPRequest(r.Method, routeTemplate(r), rw.status, rw.size, time.Since(start))
+		})
+	}
+}
+
+// TracingMiddleware создает серверный спан для каждого запроса,
+// продолжая трассу из W3C-заголовков traceparent/tracestate
+func TracingMiddleware() func(http.Handler) http.Handler {
+	tracer := otel.Tracer("data-cleaner/delivery/http")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := routeTemplate(r)
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				))
+			defer span.End()
+
+			// Оборачиваем ResponseWriter для отслеживания статуса ответа
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			span.SetAttributes(
+				attribute.Int("http.response.status_code", rw.status),
+				attribute.Int("http.response.body.size", rw.size))
+			if rw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.status))
+			}
+		})
+	}
+}
+
+// routeTemplate возвращает шаблон маршрута, чтобы не плодить метки по идентификаторам задач
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return "unmatched"
+}
+
 // ResponseWriter расширяет стандартный ResponseWriter для отслеживания статуса и размера ответа
 type responseWriter struct {
 	http.ResponseWriter
@@ -66,3 +133,8 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.size += size
 	return size, err
 }
+
+// Unwrap позволяет http.ResponseController добраться до исходного ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
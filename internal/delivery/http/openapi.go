@@ -0,0 +1,293 @@
+package http
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Спецификация OpenAPI, описывающая маршруты из Handler.RegisterRoutes
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Максимальный размер тела запроса
+const maxRequestBodySize = 1 << 20
+
+// FieldError описывает ошибку проверки отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError содержит все ошибки проверки тела запроса
+type ValidationError struct {
+	Details []FieldError
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Details))
+	for _, d := range e.Details {
+		msgs = append(msgs, d.Field+": "+d.Message)
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// openAPISpec содержит части спецификации, нужные для проверки запросов
+type openAPISpec struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas       map[string]*schema            `json:"schemas"`
+		RequestBodies map[string]openAPIRequestBody `json:"requestBodies"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	RequestBody *openAPIRequestBody `json:"requestBody"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+// schema описывает подмножество JSON Schema, используемое в спецификации
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+}
+
+// requestValidator проверяет тела запросов по спецификации OpenAPI
+type requestValidator struct {
+	spec openAPISpec
+}
+
+var openAPIValidator = mustLoadValidator(openAPIDocument)
+
+func mustLoadValidator(doc []byte) *requestValidator {
+	v := &requestValidator{}
+	if err := json.Unmarshal(doc, &v.spec); err != nil {
+		panic(fmt.Sprintf("invalid embedded OpenAPI document: %v", err))
+	}
+	return v
+}
+
+// HandleOpenAPI отдает спецификацию OpenAPI
+func (h *Handler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPIDocument)
+}
+
+// decodeRequest проверяет тело запроса по схеме маршрута и декодирует его в dst
+func decodeRequest(r *http.Request, dst interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+	if err != nil {
+		return ValidationError{Details: []FieldError{{Message: "failed to read request body"}}}
+	}
+	if len(body) > maxRequestBodySize {
+		return ValidationError{Details: []FieldError{{Message: "request body is too large"}}}
+	}
+	// Тело остается доступным для записи отказа в журнал аудита
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if details := openAPIValidator.validateBody(routeTemplate(r), r.Method, body); len(details) > 0 {
+		return ValidationError{Details: details}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return ValidationError{Details: []FieldError{{Message: err.Error()}}}
+	}
+	return nil
+}
+
+// validateBody проверяет тело запроса по схеме операции
+func (v *requestValidator) validateBody(path, method string, body []byte) []FieldError {
+	op, ok := v.spec.Paths[path][strings.ToLower(method)]
+	if !ok || op.RequestBody == nil {
+		return nil
+	}
+
+	rb := op.RequestBody
+	if rb.Ref != "" {
+		resolved := v.spec.Components.RequestBodies[strings.TrimPrefix(rb.Ref, "#/components/requestBodies/")]
+		rb = &resolved
+	}
+	media, ok := rb.Content["application/json"]
+	if !ok || media.Schema == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []FieldError{{Message: "request body must be valid JSON"}}
+	}
+	if dec.More() {
+		return []FieldError{{Message: "request body must contain a single JSON value"}}
+	}
+
+	var errs []FieldError
+	v.validate(media.Schema, value, "", &errs)
+	return errs
+}
+
+// validate рекурсивно проверяет значение по схеме
+func (v *requestValidator) validate(s *schema, value interface{}, field string, errs *[]FieldError) {
+	s = v.resolve(s)
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		fail("must not be null")
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, FieldError{Field: joinField(field, name), Message: "is required"})
+			}
+		}
+
+		// Обходим поля в постоянном порядке, чтобы ошибки были воспроизводимы
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if prop, ok := s.Properties[name]; ok {
+				v.validate(prop, obj[name], joinField(field, name), errs)
+			} else if string(s.AdditionalProperties) == "false" {
+				*errs = append(*errs, FieldError{Field: joinField(field, name), Message: "unknown field"})
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				v.validate(s.Items, item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if s.MinLength != nil && len(str) < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters long", *s.MinLength)
+			}
+			return
+		}
+		v.validateFormat(s.Format, str, fail)
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			fail("must be a %s", s.Type)
+			return
+		}
+		f, err := num.Float64()
+		if err != nil || (s.Type == "integer" && strings.ContainsAny(num.String(), ".eE")) {
+			fail("must be a %s", s.Type)
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be less than or equal to %v", *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return
+			}
+		}
+		fail("must be one of %v", s.Enum)
+	}
+}
+
+// validateFormat проверяет строку по формату схемы
+func (v *requestValidator) validateFormat(format, value string, fail func(string, ...interface{})) {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			fail("must be an RFC 3339 date-time")
+		}
+	case "duration":
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			fail("must be a non-negative duration such as \"30s\"")
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(value); err != nil || u.Scheme == "" {
+			fail("must be an absolute URI")
+		}
+	}
+}
+
+// resolve разворачивает ссылку на схему из components
+func (v *requestValidator) resolve(s *schema) *schema {
+	for s.Ref != "" {
+		resolved, ok := v.spec.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			panic(fmt.Sprintf("unresolved schema reference %q in embedded OpenAPI document", s.Ref))
+		}
+		s = resolved
+	}
+	return s
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -8,21 +8,63 @@ import (
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+
+	"data-cleaner/internal/pkg/auth"
+	"data-cleaner/internal/pkg/metrics"
 )
 
+// Таймаут записи ответа. Синхронная очистка снимает его на время работы и отсчитывает
+// заново перед ответом: ее длительность ограничивает MAX_REQUEST_TIME.
+const writeTimeout = 15 * time.Second
+
 // Server представляет HTTP-сервер
 type Server struct {
 	httpServer *http.Server
 	logger     *zap.Logger
 }
 
+// ServerOption настраивает HTTP-сервер
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	metrics       *metrics.Metrics
+	authenticator *auth.Authenticator
+}
+
+// WithMetrics включает сбор HTTP-метрик и эндпоинт /metrics
+func WithMetrics(m *metrics.Metrics) ServerOption {
+	return func(o *serverOptions) {
+		o.metrics = m
+	}
+}
+
+// WithAuthenticator включает аутентификацию запросов к API
+func WithAuthenticator(a *auth.Authenticator) ServerOption {
+	return func(o *serverOptions) {
+		o.authenticator = a
+	}
+}
+
 // NewServer создает новый HTTP-сервер
-func NewServer(handler *Handler, logger *zap.Logger, port int) *Server {
+func NewServer(handler *Handler, logger *zap.Logger, port int, opts ...ServerOption) *Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// Создаем маршрутизатор
 	router := mux.NewRouter()
 
 	// Регистрируем middleware
 	router.Use(LoggingMiddleware(logger))
+	router.Use(TracingMiddleware())
+	if options.metrics != nil {
+		router.Use(MetricsMiddleware(options.metrics))
+		router.Handle("/metrics", options.metrics.Handler()).Methods(http.MethodGet)
+	}
+	if options.authenticator != nil {
+		router.Use(AuthMiddleware(options.authenticator, handler.auditUseCase, logger))
+	}
 
 	// Регистрируем маршруты
 	handler.RegisterRoutes(router)
@@ -32,7 +74,7 @@ func NewServer(handler *Handler, logger *zap.Logger, port int) *Server {
 		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  60 * time.Second,
 	}
 
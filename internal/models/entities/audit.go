@@ -0,0 +1,212 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Результаты операций, фиксируемые в журнале аудита
+const (
+	AuditOutcomeRejected = "rejected"
+	AuditOutcomeAccepted = "accepted"
+	AuditOutcomeStarted  = "started"
+)
+
+// Идентификатор клиента, когда аутентификация отключена
+const AnonymousActor = "anonymous"
+
+// AuditEntry представляет запись журнала аудита операций очистки.
+// Записи образуют цепочку: каждая содержит хеш предыдущей.
+type AuditEntry struct {
+	Seq          int64     `json:"seq" db:"seq"`
+	RecordedAt   time.Time `json:"recorded_at" db:"recorded_at"`
+	Actor        string    `json:"actor" db:"actor"`
+	RequestID    string    `json:"request_id" db:"request_id"`
+	TaskID       string    `json:"task_id,omitempty" db:"task_id"`
+	Operation    string    `json:"operation" db:"operation"`
+	TableName    string    `json:"table_name" db:"table_name"`
+	BeforeDate   time.Time `json:"before_date" db:"before_date"`
+	BatchSize    int       `json:"batch_size" db:"batch_size"`
+	DryRun       bool      `json:"dry_run" db:"dry_run"`
+	Outcome      string    `json:"outcome" db:"outcome"`
+	RowsDeleted  int       `json:"rows_deleted" db:"rows_deleted"`
+	ErrorMessage string    `json:"error_message,omitempty" db:"error_message"`
+
+	// Параметры выполнения: параллелизм и таймауты пакета с учетом настроек, а в записи
+	// итога - результаты по секциям
+	Parallelism   int             `json:"parallelism,omitempty" db:"parallelism"`
+	BatchTimeouts BatchTimeouts   `json:"batch_timeouts" db:"batch_timeouts"`
+	Partitions    AuditPartitions `json:"partitions,omitempty" db:"partitions"`
+
+	// Запрошенный способ очистки и разрешение TRUNCATE, а в записи итога - способ,
+	// которым строки были удалены фактически
+	Strategy      string `json:"strategy,omitempty" db:"strategy"`
+	AllowTruncate bool   `json:"allow_truncate,omitempty" db:"allow_truncate"`
+	Method        string `json:"method,omitempty" db:"method"`
+
+	PrevHash string `json:"prev_hash" db:"prev_hash"`
+	Hash     string `json:"hash" db:"hash"`
+}
+
+// AuditPartition - итог очистки секции в журнале аудита
+type AuditPartition struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	RowsDeleted  int    `json:"rows_deleted"`
+	Strategy     string `json:"strategy,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// AuditPartitions хранится в журнале как JSON-массив
+type AuditPartitions []AuditPartition
+
+// NewAuditPartitions переносит в журнал итоги очистки секций
+func NewAuditPartitions(results []PartitionResult) AuditPartitions {
+	if len(results) == 0 {
+		return nil
+	}
+
+	partitions := make(AuditPartitions, len(results))
+	for i, r := range results {
+		partitions[i] = AuditPartition{
+			Name:         r.Name,
+			Status:       r.Status,
+			RowsDeleted:  r.RowsDeleted,
+			Strategy:     r.Strategy,
+			ErrorMessage: r.ErrorMessage,
+		}
+	}
+	return partitions
+}
+
+// Value записывает итоги секций в базу; пустой список хранится как []
+func (p AuditPartitions) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]AuditPartition(p))
+	return string(data), err
+}
+
+// Scan читает итоги секций из базы
+func (p *AuditPartitions) Scan(src interface{}) error {
+	*p = nil
+	return scanJSON(src, (*[]AuditPartition)(p))
+}
+
+// AuditFilter задает параметры выборки записей журнала
+type AuditFilter struct {
+	AfterSeq  int64
+	TableName string
+	Limit     int
+}
+
+// AuditProblem описывает нарушение целостности журнала
+type AuditProblem struct {
+	Seq    int64  `json:"seq"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// AuditVerification представляет результат проверки цепочки журнала
+type AuditVerification struct {
+	Valid    bool           `json:"valid"`
+	Checked  int            `json:"checked"`
+	LastSeq  int64          `json:"last_seq"`
+	LastHash string         `json:"last_hash"`
+	Problems []AuditProblem `json:"problems,omitempty"`
+}
+
+// Виды нарушений целостности журнала
+const (
+	AuditProblemGap          = "gap"
+	AuditProblemBrokenChain  = "broken_chain"
+	AuditProblemHashMismatch = "hash_mismatch"
+)
+
+// ComputeHash вычисляет хеш записи вместе с хешем предыдущей записи.
+// Время приводится к UTC с точностью до микросекунд, как оно хранится в базе.
+func (e AuditEntry) ComputeHash() string {
+	canonical := struct {
+		Seq          int64  `json:"seq"`
+		RecordedAt   string `json:"recorded_at"`
+		Actor        string `json:"actor"`
+		RequestID    string `json:"request_id"`
+		TaskID       string `json:"task_id"`
+		Operation    string `json:"operation"`
+		TableName    string `json:"table_name"`
+		BeforeDate   string `json:"before_date"`
+		BatchSize    int    `json:"batch_size"`
+		DryRun       bool   `json:"dry_run"`
+		Outcome      string `json:"outcome"`
+		RowsDeleted  int    `json:"rows_deleted"`
+		ErrorMessage string `json:"error_message"`
+
+		// Поля, добавленные позже, пропускаются в нулевом значении, поэтому хеши
+		// ранее записанных записей не меняются
+		Parallelism   int              `json:"parallelism,omitempty"`
+		BatchTimeouts *BatchTimeouts   `json:"batch_timeouts,omitempty"`
+		Partitions    []AuditPartition `json:"partitions,omitempty"`
+		Strategy      string           `json:"strategy,omitempty"`
+		AllowTruncate bool             `json:"allow_truncate,omitempty"`
+		Method        string           `json:"method,omitempty"`
+
+		PrevHash string `json:"prev_hash"`
+	}{
+		Seq:           e.Seq,
+		RecordedAt:    canonicalTime(e.RecordedAt),
+		Actor:         e.Actor,
+		RequestID:     e.RequestID,
+		TaskID:        e.TaskID,
+		Operation:     e.Operation,
+		TableName:     e.TableName,
+		BeforeDate:    canonicalTime(e.BeforeDate),
+		BatchSize:     e.BatchSize,
+		DryRun:        e.DryRun,
+		Outcome:       e.Outcome,
+		RowsDeleted:   e.RowsDeleted,
+		ErrorMessage:  e.ErrorMessage,
+		Parallelism:   e.Parallelism,
+		Partitions:    e.Partitions,
+		Strategy:      e.Strategy,
+		AllowTruncate: e.AllowTruncate,
+		Method:        e.Method,
+		PrevHash:      e.PrevHash,
+	}
+	if e.BatchTimeouts != (BatchTimeouts{}) {
+		timeouts := e.BatchTimeouts
+		canonical.BatchTimeouts = &timeouts
+	}
+
+	// Маршалинг структуры с фиксированным набором полей детерминирован
+	data, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func canonicalTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// scanJSON разбирает значение JSON-колонки; NULL оставляет dest нулевым
+func scanJSON(src interface{}, dest interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported JSON column type %T", src)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
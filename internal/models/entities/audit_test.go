@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func legacyAuditEntry() AuditEntry {
+	return AuditEntry{
+		Seq:         7,
+		RecordedAt:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Actor:       "ops",
+		RequestID:   "req-1",
+		TaskID:      "task-1",
+		Operation:   ModeAsync,
+		TableName:   "public.events",
+		BeforeDate:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		BatchSize:   5000,
+		Outcome:     StatusCompleted,
+		RowsDeleted: 42,
+		PrevHash:    "abc",
+	}
+}
+
+func TestAuditEntryHashKeepsLegacyEntries(t *testing.T) {
+	// Хеш записи, сделанной до появления параметров выполнения
+	const want = "6867529d786f82e9d28d38a65db57da7b63b8027a208eef86cd13f40298f4a60"
+
+	if got := legacyAuditEntry().ComputeHash(); got != want {
+		t.Errorf("ComputeHash() = %s, want %s", got, want)
+	}
+}
+
+func TestAuditEntryHashCoversRunParameters(t *testing.T) {
+	base := legacyAuditEntry().ComputeHash()
+
+	tests := []struct {
+		name   string
+		modify func(e *AuditEntry)
+	}{
+		{"parallelism", func(e *AuditEntry) { e.Parallelism = 4 }},
+		{"batch timeouts", func(e *AuditEntry) { e.BatchTimeouts.Lock = Duration(10 * time.Second) }},
+		{"partition result", func(e *AuditEntry) {
+			e.Partitions = AuditPartitions{{Name: "events_p1", Status: StatusCompleted, RowsDeleted: 42}}
+		}},
+		{"strategy", func(e *AuditEntry) { e.Strategy = StrategyCopySwap }},
+		{"allow truncate", func(e *AuditEntry) { e.AllowTruncate = true }},
+		{"method", func(e *AuditEntry) { e.Method = StrategyTruncate }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := legacyAuditEntry()
+			tt.modify(&e)
+			if e.ComputeHash() == base {
+				t.Errorf("hash does not change with %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestAuditPartitionsRoundTrip(t *testing.T) {
+	want := AuditPartitions{
+		{Name: "events_p1", Status: StatusCompleted, RowsDeleted: 10, Strategy: StrategyTruncate},
+		{Name: "events_p2", Status: StatusFailed, ErrorMessage: "lock timeout"},
+	}
+
+	value, err := want.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got AuditPartitions
+	if err := got.Scan([]byte(value.(string))); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	// Колонки прежних записей пусты или NULL
+	for _, src := range []interface{}{nil, "", "[]"} {
+		if err := got.Scan(src); err != nil || len(got) != 0 {
+			t.Errorf("Scan(%#v) = %+v, %v; want empty", src, got, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"context"
+	"time"
+)
+
+// Режимы выполнения операции очистки, на которые выдаются права
+const (
+	ModeSync   = "sync"
+	ModeAsync  = "async"
+	ModeDryRun = "dry_run"
+)
+
+// AuthScope описывает права ключа доступа
+type AuthScope struct {
+	// Tables содержит имена или glob-шаблоны таблиц, которые разрешено очищать.
+	// Имена сопоставляются так же, как в TablePolicy: без схемы подразумевается public.
+	Tables []string
+	// MinRetention запрещает удалять данные моложе указанного срока
+	MinRetention time.Duration
+	// Modes перечисляет разрешенные режимы: sync, async, dry_run
+	Modes []string
+	// Admin разрешает читать журнал аудита и задачи, запущенные другими клиентами
+	Admin bool
+}
+
+// Principal представляет аутентифицированного клиента
+type Principal struct {
+	ID    string
+	Scope AuthScope
+}
+
+// AllowsTable проверяет, разрешено ли очищать таблицу
+func (s AuthScope) AllowsTable(tableName string) bool {
+	for _, pattern := range s.Tables {
+		if MatchTable(pattern, tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsMode проверяет, разрешен ли режим выполнения
+func (s AuthScope) AllowsMode(mode string) bool {
+	for _, m := range s.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsCutoff проверяет, что граница удаления не затрагивает защищенный срок хранения
+func (s AuthScope) AllowsCutoff(beforeDate, now time.Time) bool {
+	return !beforeDate.After(now.Add(-s.MinRetention))
+}
+
+type principalKey struct{}
+
+// WithPrincipal добавляет клиента в контекст
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext извлекает клиента из контекста
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// ForbiddenError представляет отказ в доступе к операции
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
+
+func NewForbiddenError(message string) ForbiddenError {
+	return ForbiddenError{Message: message}
+}
@@ -0,0 +1,31 @@
+package entities
+
+import "testing"
+
+func TestAuthScopeAllowsTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		table    string
+		want     bool
+	}{
+		{"bare pattern, bare name", []string{"users"}, "users", true},
+		{"bare pattern, qualified name", []string{"users"}, "public.users", true},
+		{"bare pattern, mixed case", []string{"users"}, "Public.Users", true},
+		{"schema glob, bare name", []string{"public.*"}, "users", true},
+		{"schema glob, other schema", []string{"public.*"}, "audit.users", false},
+		{"other schema pattern", []string{"audit.*"}, "users", false},
+		{"any schema", []string{"*.users"}, "audit.users", true},
+		{"all tables of all schemas", []string{"*.*"}, "audit.events", true},
+		{"no patterns", nil, "users", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := AuthScope{Tables: tt.patterns}
+			if got := scope.AllowsTable(tt.table); got != tt.want {
+				t.Errorf("AllowsTable(%q) with %v = %v, want %v", tt.table, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
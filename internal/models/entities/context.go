@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"context"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID добавляет идентификатор HTTP-запроса в контекст
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext извлекает идентификатор HTTP-запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey добавляет ключ идемпотентности запроса в контекст
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext извлекает ключ идемпотентности из контекста
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
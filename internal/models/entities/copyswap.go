@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// CopySwap описывает подготовленную очистку заменой таблицы: сохраняемые строки
+// копируются в новую таблицу, а изменения исходной таблицы за время копирования
+// записываются триггером в журнал и переносятся перед заменой
+type CopySwap struct {
+	Table      string    // Исходная таблица
+	Shadow     string    // Новая таблица, которая заменит исходную
+	DeltaLog   string    // Журнал изменений исходной таблицы
+	BeforeDate time.Time // Строки старше этой даты не копируются
+}
+
+// Этапы очистки заменой, о которых сообщают события хода выполнения
+const (
+	PhaseCopy         = "copy"          // Копирование сохраняемых строк
+	PhaseBuildIndexes = "build_indexes" // Построение индексов новой таблицы
+	PhaseDeltaReplay  = "delta_replay"  // Перенос изменений из журнала
+	PhaseSwap         = "swap"          // Замена таблиц
+)
+
+// ErrCopySwapNotSupported возвращается, если драйвер базы данных не поддерживает очистку заменой
+var ErrCopySwapNotSupported = NewDomainError("copy_swap strategy is only supported for PostgreSQL")
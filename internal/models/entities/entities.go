@@ -1,23 +1,130 @@
 package entities
 
 import (
+	"fmt"
+	"math"
+	"net/url"
 	"time"
 )
 
 // CleanupRequest представляет запрос на удаление данных
 type CleanupRequest struct {
-	TableName  string    `json:"table_name"`
-	BeforeDate time.Time `json:"before_date"`
-	BatchSize  int       `json:"batch_size"`
+	TableName   string    `json:"table_name"`
+	BeforeDate  time.Time `json:"before_date"`
+	BatchSize   int       `json:"batch_size"`
+	DryRun      bool      `json:"dry_run"`
+	CallbackURL string    `json:"callback_url,omitempty"`
+
+	// Таймауты транзакции пакета; незаданные берутся из настроек таблицы или значений по умолчанию
+	Timeouts BatchTimeouts `json:"timeouts,omitempty"`
+
+	// Число секций, очищаемых параллельно; 0 или 1 - последовательная очистка через родительскую таблицу
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// Разрешает очищать командой TRUNCATE таблицу или секцию, все записи которой старше before_date
+	AllowTruncate bool `json:"allow_truncate,omitempty"`
+
+	// Способ очистки: batch_delete (по умолчанию) или copy_swap
+	Strategy string `json:"strategy,omitempty"`
 }
 
+// Статусы операции очистки
+const (
+	StatusPending          = "pending"
+	StatusWaitingForWindow = "waiting_for_window"
+	StatusInProgress       = "in_progress"
+	StatusCompleted        = "completed"
+	StatusFailed           = "failed"
+	StatusCanceled         = "canceled"
+	StatusTimedOut         = "timed_out"
+	StatusDryRun           = "dry_run"
+
+	// Очистка завершена, но проверка нашла записи старше before_date
+	StatusCompletedWithLeftovers = "completed_with_leftovers"
+)
+
+// Способы очистки таблицы или секции
+const (
+	StrategyBatchDelete = "batch_delete" // Пакетное удаление
+	StrategyTruncate    = "truncate"     // TRUNCATE: все записи старше before_date
+	StrategyMixed       = "mixed"        // Часть секций очищена TRUNCATE, часть - пакетами
+	StrategyCopySwap    = "copy_swap"    // Сохраняемые строки скопированы в новую таблицу, заменившую исходную
+)
+
 // CleanupResult представляет результат операции удаления
 type CleanupResult struct {
 	TableName    string        `json:"table_name"`
 	RowsDeleted  int           `json:"rows_deleted"`
+	RowsMatched  int           `json:"rows_matched,omitempty"`
 	ElapsedTime  time.Duration `json:"elapsed_time"`
 	Status       string        `json:"status"`
 	ErrorMessage string        `json:"error_message,omitempty"`
+
+	// Ход выполнения: оценка объема, процент, скорость и оставшееся время
+	RowsEstimated   int           `json:"rows_estimated,omitempty"`
+	PercentComplete float64       `json:"percent_complete"`
+	RowsPerSecond   float64       `json:"rows_per_second,omitempty"`
+	ETA             time.Duration `json:"eta,omitempty"`
+
+	// Строк скопировано в новую таблицу при очистке заменой
+	RowsCopied int `json:"rows_copied,omitempty"`
+
+	// Повторы пакетов после временных ошибок базы данных
+	Retries            int    `json:"retries,omitempty"`
+	LastTransientError string `json:"last_transient_error,omitempty"`
+
+	// Результаты по секциям при параллельной очистке
+	Partitions []PartitionResult `json:"partitions,omitempty"`
+
+	// Выбранный способ очистки и, если TRUNCATE был разрешен, но не применен, причина
+	Strategy       string `json:"strategy,omitempty"`
+	StrategyReason string `json:"strategy_reason,omitempty"`
+
+	// Проверка после завершенной очистки
+	Verified *Verification `json:"verified,omitempty"`
+}
+
+// Verification описывает проверку таблицы после очистки: сколько записей старше
+// before_date осталось и сходится ли это с числом удаленных строк
+type Verification struct {
+	RowsRemaining int       `json:"rows_remaining"`
+	RowsDeleted   int       `json:"rows_deleted"`
+	RowsEstimated int       `json:"rows_estimated,omitempty"`
+	Mismatch      bool      `json:"mismatch"`
+	CheckedAt     time.Time `json:"checked_at"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// PartitionResult описывает очистку одной секции при параллельном выполнении
+type PartitionResult struct {
+	Name         string        `json:"name"`
+	Status       string        `json:"status"`
+	RowsDeleted  int           `json:"rows_deleted"`
+	ElapsedTime  time.Duration `json:"elapsed_time"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+
+	Strategy       string `json:"strategy,omitempty"`
+	StrategyReason string `json:"strategy_reason,omitempty"`
+}
+
+// UpdateProgress пересчитывает процент выполнения, скорость и оставшееся время
+func (r *CleanupResult) UpdateProgress(elapsed time.Duration) {
+	if elapsed > 0 {
+		r.RowsPerSecond = float64(r.RowsDeleted) / elapsed.Seconds()
+	}
+
+	if r.RowsEstimated <= 0 {
+		return
+	}
+
+	r.PercentComplete = math.Min(100, float64(r.RowsDeleted)*100/float64(r.RowsEstimated))
+
+	remaining := r.RowsEstimated - r.RowsDeleted
+	if remaining > 0 && r.RowsPerSecond > 0 {
+		r.ETA = time.Duration(float64(remaining) / r.RowsPerSecond * float64(time.Second))
+	} else {
+		r.ETA = 0
+	}
 }
 
 // Validate проверяет корректность запроса
@@ -34,16 +141,90 @@ func (r *CleanupRequest) Validate() error {
 		return ErrInvalidBatchSize
 	}
 
+	if err := r.Timeouts.Validate(); err != nil {
+		return err
+	}
+
+	if r.Parallelism < 0 {
+		return ErrInvalidParallelism
+	}
+
+	switch r.Strategy {
+	case "", StrategyBatchDelete, StrategyCopySwap:
+	default:
+		return ErrInvalidStrategy
+	}
+
+	if r.CallbackURL != "" {
+		u, err := url.Parse(r.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidCallbackURL
+		}
+	}
+
 	return nil
 }
 
+// Mode возвращает режим выполнения запроса для проверки прав
+func (r *CleanupRequest) Mode(async bool) string {
+	switch {
+	case r.DryRun:
+		return ModeDryRun
+	case async:
+		return ModeAsync
+	default:
+		return ModeSync
+	}
+}
+
 // Domain errors
 var (
-	ErrEmptyTableName   = NewDomainError("table name cannot be empty")
-	ErrInvalidDate      = NewDomainError("invalid date specified")
-	ErrInvalidBatchSize = NewDomainError("batch size must be positive")
+	ErrEmptyTableName       = NewDomainError("table name cannot be empty")
+	ErrInvalidDate          = NewDomainError("invalid date specified")
+	ErrInvalidBatchSize     = NewDomainError("batch size must be positive")
+	ErrInvalidCallbackURL   = NewDomainError("callback_url must be an absolute http or https URL")
+	ErrCallbackNotSupported = NewDomainError("callback_url is only supported for async cleanups")
+	ErrCallbacksDisabled    = NewDomainError("callback_url is not accepted: per-request callbacks are disabled")
+	ErrCallbackNotHTTPS     = NewDomainError("callback_url must use https")
+	ErrCallbackHostDenied   = NewDomainError("callback_url host is not in the allowed callback hosts")
+	ErrCallbackAddress      = NewDomainError("callback_url must not point to a loopback, link-local or private address")
+	ErrInvalidParallelism   = NewDomainError("parallelism must not be negative")
+	ErrInvalidStrategy      = NewDomainError("strategy must be batch_delete or copy_swap")
 )
 
+// TableInfo описывает таблицу, доступную для очистки
+type TableInfo struct {
+	Name          string `json:"name" db:"name"`
+	EstimatedRows int64  `json:"estimated_rows" db:"estimated_rows"`
+	HasDateIndex  bool   `json:"has_date_index" db:"has_date_index"`
+	Partitioned   bool   `json:"partitioned" db:"partitioned"`
+}
+
+// LockConflictError возвращается, когда таблицу уже очищает другой процесс
+type LockConflictError struct {
+	Table string
+}
+
+func (e LockConflictError) Error() string {
+	return fmt.Sprintf("another process is already cleaning table %s", e.Table)
+}
+
+// Виды объектов для NotFoundError
+const (
+	ResourceTable = "table"
+	ResourceTask  = "task"
+)
+
+// NotFoundError возвращается, когда запрошенная таблица или задача не существует
+type NotFoundError struct {
+	Resource string
+	Name     string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Resource, e.Name)
+}
+
 // DomainError представляет ошибку предметной области
 type DomainError struct {
 	Message string
@@ -56,3 +237,29 @@ func (e DomainError) Error() string {
 func NewDomainError(message string) DomainError {
 	return DomainError{Message: message}
 }
+
+// BatchCursor - позиция keyset-курсора пакетного удаления: ключ (created_at, id)
+// последней обработанной строки. Нулевой курсор означает начало диапазона.
+type BatchCursor struct {
+	CreatedAt time.Time
+	ID        string // id в текстовом виде, чтобы поддерживать числовые и UUID-ключи
+}
+
+// IsZero сообщает, что курсор указывает на начало диапазона
+func (c BatchCursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == ""
+}
+
+// BatchResult описывает итог удаления одного пакета
+type BatchResult struct {
+	Deleted   int         // Удалено строк
+	Next      BatchCursor // Курсор для следующего пакета
+	Exhausted bool        // За курсором не осталось подходящих строк
+}
+
+// TruncateResult описывает попытку очистить таблицу командой TRUNCATE
+type TruncateResult struct {
+	Truncated bool   // Таблица очищена
+	Rows      int    // Сколько строк было в таблице
+	Reason    string // Почему таблица не очищена
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCleanupResultUpdateProgress(t *testing.T) {
+	tests := []struct {
+		name          string
+		deleted       int
+		estimated     int
+		elapsed       time.Duration
+		wantPercent   float64
+		wantPerSecond float64
+		wantETA       time.Duration
+	}{
+		{
+			name:          "halfway",
+			deleted:       500,
+			estimated:     1000,
+			elapsed:       10 * time.Second,
+			wantPercent:   50,
+			wantPerSecond: 50,
+			wantETA:       10 * time.Second,
+		},
+		{
+			name:          "more rows than estimated",
+			deleted:       1500,
+			estimated:     1000,
+			elapsed:       10 * time.Second,
+			wantPercent:   100,
+			wantPerSecond: 150,
+		},
+		{
+			// Без оценки объема процент и оставшееся время не пересчитываются
+			name:          "no estimate",
+			deleted:       300,
+			elapsed:       3 * time.Second,
+			wantPerSecond: 100,
+			wantETA:       time.Hour,
+		},
+		{
+			name:        "nothing deleted yet",
+			estimated:   1000,
+			elapsed:     time.Second,
+			wantPercent: 0,
+		},
+		{
+			name:        "no time elapsed",
+			deleted:     10,
+			estimated:   40,
+			wantPercent: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Оставшееся время от прошлого пересчета
+			r := CleanupResult{RowsDeleted: tt.deleted, RowsEstimated: tt.estimated, ETA: time.Hour}
+			r.UpdateProgress(tt.elapsed)
+
+			if math.Abs(r.PercentComplete-tt.wantPercent) > 1e-9 {
+				t.Errorf("PercentComplete = %v, want %v", r.PercentComplete, tt.wantPercent)
+			}
+			if math.Abs(r.RowsPerSecond-tt.wantPerSecond) > 1e-9 {
+				t.Errorf("RowsPerSecond = %v, want %v", r.RowsPerSecond, tt.wantPerSecond)
+			}
+			if r.ETA != tt.wantETA {
+				t.Errorf("ETA = %v, want %v", r.ETA, tt.wantETA)
+			}
+		})
+	}
+}
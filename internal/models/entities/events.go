@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+)
+
+// Типы событий хода выполнения задачи
+const (
+	EventStatus         = "status"
+	EventBatchCompleted = "batch_completed"
+	EventBatchCopied    = "batch_copied"
+	EventPhase          = "phase"
+	EventPaused         = "paused"
+	EventResumed        = "resumed"
+	EventRetrying       = "retrying"
+	EventFinished       = "finished"
+)
+
+// ProgressEvent представляет событие хода выполнения задачи очистки
+type ProgressEvent struct {
+	Type          string         `json:"type"`
+	TaskID        string         `json:"task_id"`
+	Time          time.Time      `json:"time"`
+	Status        string         `json:"status"`
+	BatchRows     int            `json:"batch_rows,omitempty"`
+	RowsDeleted   int            `json:"rows_deleted"`
+	RowsPerSecond float64        `json:"rows_per_second,omitempty"`
+	Percent       float64        `json:"percent_complete"`
+	ETA           time.Duration  `json:"eta,omitempty"`
+	Reason        string         `json:"reason,omitempty"`
+	Phase         string         `json:"phase,omitempty"`
+	Result        *CleanupResult `json:"result,omitempty"`
+}
+
+// IsTerminalStatus проверяет, является ли статус задачи окончательным
+func IsTerminalStatus(status string) bool {
+	switch status {
+	case StatusCompleted, StatusCompletedWithLeftovers, StatusFailed, StatusCanceled, StatusTimedOut, StatusDryRun:
+		return true
+	default:
+		return false
+	}
+}
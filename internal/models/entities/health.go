@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+)
+
+// Статусы проверок работоспособности
+const (
+	HealthStatusOK        = "ok"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// HealthReport представляет результат проверки работоспособности сервиса
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Time   time.Time              `json:"time"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+	Pool   *PoolStats             `json:"pool,omitempty"`
+	Tasks  *TaskStats             `json:"tasks,omitempty"`
+	Build  *BuildInfo             `json:"build,omitempty"`
+}
+
+// BuildInfo содержит сведения о сборке приложения
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// HealthCheck представляет результат проверки одной зависимости
+type HealthCheck struct {
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// PoolStats содержит статистику пула соединений с базой данных
+type PoolStats struct {
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	Saturation         float64       `json:"saturation"`
+}
+
+// TaskStats содержит количество задач очистки по состояниям
+type TaskStats struct {
+	Running int `json:"running"`
+	Queued  int `json:"queued"`
+}
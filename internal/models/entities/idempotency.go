@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Максимальная длина ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+// ErrInvalidIdempotencyKey возвращается для пустого, слишком длинного или непечатного ключа
+var ErrInvalidIdempotencyKey = NewDomainError(
+	fmt.Sprintf("idempotency key must be 1-%d printable ASCII characters", maxIdempotencyKeyLength))
+
+// ValidateIdempotencyKey проверяет формат ключа идемпотентности
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return ErrInvalidIdempotencyKey
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return ErrInvalidIdempotencyKey
+		}
+	}
+	return nil
+}
+
+// IdempotencyConflictError возвращается, когда ключ уже использован с другим телом запроса
+type IdempotencyConflictError struct {
+	Key string
+}
+
+func (e IdempotencyConflictError) Error() string {
+	return fmt.Sprintf("idempotency key %q was already used with a different request", e.Key)
+}
+
+// Fingerprint возвращает отпечаток запроса для сравнения повторных отправок
+func (r *CleanupRequest) Fingerprint() string {
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
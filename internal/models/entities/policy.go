@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Схема, к которой относятся имена таблиц без явного указания схемы
+const DefaultSchema = "public"
+
+// ServiceTables - шаблоны служебных таблиц сервиса: журнала аудита, недоставленных
+// уведомлений, блокировок SQLite и вспомогательных таблиц очистки заменой. Они защищены
+// всегда, даже если denylist переопределен в конфигурации: триггеры запрещают изменять
+// только журнал аудита.
+var ServiceTables = []string{
+	"*.cleanup_audit_log",
+	"*.webhook_dead_letters",
+	"*.cleanup_locks",
+	"*.cs_????????_new",
+	"*.cs_????????_delta",
+}
+
+// TablePolicy задает списки разрешенных и защищенных таблиц.
+// Шаблоны поддерживают glob-синтаксис и могут содержать схему ("audit.*", "*.secrets").
+type TablePolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// TablePolicyError возвращается, когда таблица запрещена политикой
+type TablePolicyError struct {
+	Table  string
+	Reason string
+}
+
+func (e TablePolicyError) Error() string {
+	return fmt.Sprintf("table %s is not allowed: %s", e.Table, e.Reason)
+}
+
+// QualifiedTableName дополняет имя таблицы схемой по умолчанию
+func QualifiedTableName(name string) string {
+	if strings.Contains(name, ".") {
+		return strings.ToLower(name)
+	}
+	return DefaultSchema + "." + strings.ToLower(name)
+}
+
+// Check проверяет, разрешена ли очистка таблицы. Запрет имеет приоритет над разрешением,
+// пустой список разрешенных таблиц допускает любые таблицы, кроме запрещенных и служебных.
+func (p TablePolicy) Check(tableName string) error {
+	qualified := QualifiedTableName(tableName)
+
+	if pattern, ok := matchAny(ServiceTables, qualified); ok {
+		return TablePolicyError{Table: tableName, Reason: fmt.Sprintf("service table protected by pattern %q", pattern)}
+	}
+
+	if pattern, ok := matchAny(p.Deny, qualified); ok {
+		return TablePolicyError{Table: tableName, Reason: fmt.Sprintf("protected by denylist pattern %q", pattern)}
+	}
+
+	if len(p.Allow) > 0 {
+		if _, ok := matchAny(p.Allow, qualified); !ok {
+			return TablePolicyError{Table: tableName, Reason: "not in allowlist"}
+		}
+	}
+
+	return nil
+}
+
+// Validate проверяет корректность шаблонов политики
+func (p TablePolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if _, err := path.Match(QualifiedTableName(pattern), ""); err != nil {
+			return fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchTable проверяет, соответствует ли таблица glob-шаблону. Шаблон и имя таблицы
+// без схемы дополняются схемой по умолчанию, поэтому "users" и "public.users" совпадают,
+// а таблицы всех схем описывает шаблон "*.*".
+func MatchTable(pattern, tableName string) bool {
+	ok, _ := path.Match(QualifiedTableName(pattern), QualifiedTableName(tableName))
+	return ok
+}
+
+// matchAny возвращает первый шаблон, которому соответствует полное имя таблицы
+func matchAny(patterns []string, qualified string) (string, bool) {
+	for _, pattern := range patterns {
+		if MatchTable(pattern, qualified) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
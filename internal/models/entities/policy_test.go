@@ -0,0 +1,37 @@
+package entities
+
+import "testing"
+
+func TestTablePolicyCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  TablePolicy
+		table   string
+		allowed bool
+	}{
+		{"allowed bare name", TablePolicy{Allow: []string{"public.*"}}, "events", true},
+		{"allowed qualified name", TablePolicy{Allow: []string{"public.*"}}, "public.events", true},
+		{"not in allowlist", TablePolicy{Allow: []string{"public.*"}}, "audit.events", false},
+		{"denied bare name", TablePolicy{Deny: []string{"users"}}, "users", false},
+		{"denied mixed case", TablePolicy{Deny: []string{"users"}}, "Public.Users", false},
+		{"denied in any schema", TablePolicy{Deny: []string{"*.secrets"}}, "secrets", false},
+		{"deny wins over allow", TablePolicy{Allow: []string{"*"}, Deny: []string{"users"}}, "users", false},
+		{"empty policy", TablePolicy{}, "events", true},
+		{"audit log", TablePolicy{}, "cleanup_audit_log", false},
+		{"audit log in allowlist", TablePolicy{Allow: []string{"*"}}, "public.cleanup_audit_log", false},
+		{"dead letters", TablePolicy{}, "webhook_dead_letters", false},
+		{"sqlite locks", TablePolicy{}, "cleanup_locks", false},
+		{"copy and swap shadow", TablePolicy{}, "cs_0a1b2c3d_new", false},
+		{"copy and swap delta", TablePolicy{}, "app.cs_0a1b2c3d_delta", false},
+		{"similar user table", TablePolicy{}, "cs_events_new", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.table)
+			if (err == nil) != tt.allowed {
+				t.Errorf("Check(%q) = %v, want allowed=%v", tt.table, err, tt.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// TransientError оборачивает ошибку базы данных, после которой операцию можно повторить:
+// взаимоблокировку, конфликт сериализации, разрыв соединения
+type TransientError struct {
+	// Code содержит код ошибки СУБД (SQLSTATE или номер ошибки), если он известен
+	Code string
+	Err  error
+}
+
+func (e TransientError) Error() string {
+	if e.Code == "" {
+		return "transient database error: " + e.Err.Error()
+	}
+	return "transient database error (" + e.Code + "): " + e.Err.Error()
+}
+
+func (e TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient проверяет, можно ли повторить операцию после ошибки
+func IsTransient(err error) bool {
+	var transient TransientError
+	return errors.As(err, &transient)
+}
+
+// RetryPolicy задает повторы пакетов после временных ошибок базы данных
+type RetryPolicy struct {
+	MaxRetries int           // Сколько раз подряд можно повторить один пакет; 0 отключает повторы
+	BaseDelay  time.Duration // Задержка перед первым повтором
+	MaxDelay   time.Duration // Предельная задержка между повторами
+}
+
+// Backoff возвращает задержку перед повтором с номером attempt (начиная с 1):
+// экспоненциальный рост от BaseDelay до MaxDelay со случайным разбросом в пределах половины значения
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
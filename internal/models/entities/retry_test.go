@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration // Задержка без разброса; фактическая лежит в [want/2, want]
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := policy.Backoff(tt.attempt); got < tt.want/2 || got > tt.want {
+					t.Fatalf("Backoff(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+
+	if got := (RetryPolicy{}).Backoff(1); got != 0 {
+		t.Errorf("Backoff without delays = %v, want 0", got)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cause := errors.New("deadlock detected")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"transient", TransientError{Code: "40P01", Err: cause}, true},
+		{"wrapped transient", fmt.Errorf("delete batch: %w", TransientError{Err: cause}), true},
+		{"plain error", cause, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
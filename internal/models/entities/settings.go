@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"time"
+)
+
+// RuntimeSettings содержит настройки очистки, которые можно менять без перезапуска сервиса
+type RuntimeSettings struct {
+	DefaultBatchSize   int                 // Размер пакета, если он не указан в запросе
+	MaxRequestTime     time.Duration       // Предельное время синхронной очистки
+	BatchPause         time.Duration       // Пауза между пакетами для снижения нагрузки на базу
+	TablePolicy        TablePolicy         // Списки разрешенных и защищенных таблиц
+	MaintenanceWindows MaintenanceSchedule // Окна обслуживания
+	IdempotencyKeyTTL  time.Duration       // Срок хранения ключей идемпотентности асинхронных задач
+	Retry              RetryPolicy         // Повторы пакетов после временных ошибок базы данных
+	BatchTimeouts      TimeoutPolicy       // Таймауты транзакции пакета по умолчанию и для таблиц
+	MaxParallelism     int                 // Предел параллельно очищаемых секций одной задачи
+}
+
+// DefaultRuntimeSettings возвращает настройки, используемые без конфигурации.
+// Значения по умолчанию конфигурации берутся отсюда же.
+func DefaultRuntimeSettings() RuntimeSettings {
+	return RuntimeSettings{
+		DefaultBatchSize:  5000,
+		MaxRequestTime:    30 * time.Minute,
+		BatchPause:        100 * time.Millisecond,
+		IdempotencyKeyTTL: 24 * time.Hour,
+		Retry: RetryPolicy{
+			MaxRetries: 5,
+			BaseDelay:  200 * time.Millisecond,
+			MaxDelay:   10 * time.Second,
+		},
+		BatchTimeouts: TimeoutPolicy{
+			Default: BatchTimeouts{
+				Statement:         Duration(30 * time.Second),
+				Lock:              Duration(10 * time.Second),
+				IdleInTransaction: Duration(time.Minute),
+			},
+		},
+		MaxParallelism: 4,
+	}
+}
@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration - длительность, которая в JSON записывается строкой ("5s", "1m30s")
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("duration must be a string such as \"5s\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// BatchTimeouts задает серверные ограничения времени для транзакции одного пакета.
+// Нулевое значение означает, что ограничение берется из настроек или не устанавливается.
+type BatchTimeouts struct {
+	Statement         Duration `json:"statement,omitempty"`           // statement_timeout
+	Lock              Duration `json:"lock,omitempty"`                // lock_timeout
+	IdleInTransaction Duration `json:"idle_in_transaction,omitempty"` // idle_in_transaction_session_timeout
+}
+
+// Value записывает таймауты в журнал аудита как JSON-объект
+func (t BatchTimeouts) Value() (driver.Value, error) {
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+// Scan читает таймауты из журнала аудита
+func (t *BatchTimeouts) Scan(src interface{}) error {
+	*t = BatchTimeouts{}
+	return scanJSON(src, t)
+}
+
+// ErrInvalidTimeouts возвращается для отрицательных таймаутов пакета
+var ErrInvalidTimeouts = NewDomainError("batch timeouts must not be negative")
+
+// Validate проверяет, что таймауты не отрицательны
+func (t BatchTimeouts) Validate() error {
+	if t.Statement < 0 || t.Lock < 0 || t.IdleInTransaction < 0 {
+		return ErrInvalidTimeouts
+	}
+	return nil
+}
+
+// Or дополняет незаданные таймауты значениями из fallback
+func (t BatchTimeouts) Or(fallback BatchTimeouts) BatchTimeouts {
+	if t.Statement == 0 {
+		t.Statement = fallback.Statement
+	}
+	if t.Lock == 0 {
+		t.Lock = fallback.Lock
+	}
+	if t.IdleInTransaction == 0 {
+		t.IdleInTransaction = fallback.IdleInTransaction
+	}
+	return t
+}
+
+// TimeoutPolicy задает таймауты пакетов по умолчанию и для отдельных таблиц
+type TimeoutPolicy struct {
+	Default BatchTimeouts
+	Tables  map[string]BatchTimeouts
+}
+
+// For возвращает таймауты для таблицы: настройки таблицы дополняются значениями по умолчанию
+func (p TimeoutPolicy) For(tableName string) BatchTimeouts {
+	return p.Tables[strings.ToLower(tableName)].Or(p.Default)
+}
+
+// Виды таймаутов пакета
+const (
+	TimeoutStatement         = "statement"
+	TimeoutLock              = "lock"
+	TimeoutIdleInTransaction = "idle_in_transaction"
+	TimeoutClient            = "client"
+)
+
+// TimeoutError возвращается, когда пакет прерван по таймауту: серверному
+// (statement, lock, idle_in_transaction) или клиентскому
+type TimeoutError struct {
+	Kind string
+	Err  error
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout exceeded: %v", e.Kind, e.Err)
+}
+
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
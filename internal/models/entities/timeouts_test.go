@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeoutPolicyFor(t *testing.T) {
+	policy := TimeoutPolicy{
+		Default: BatchTimeouts{Statement: Duration(30 * time.Second), Lock: Duration(5 * time.Second)},
+		Tables: map[string]BatchTimeouts{
+			"events": {Statement: Duration(2 * time.Minute)},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		table string
+		want  BatchTimeouts
+	}{
+		{"table without settings", "users", policy.Default},
+		{"table settings complemented by defaults", "events", BatchTimeouts{Statement: Duration(2 * time.Minute), Lock: Duration(5 * time.Second)}},
+		{"table name is case insensitive", "Events", BatchTimeouts{Statement: Duration(2 * time.Minute), Lock: Duration(5 * time.Second)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.For(tt.table); got != tt.want {
+				t.Errorf("For(%q) = %+v, want %+v", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchTimeoutsJSON(t *testing.T) {
+	var got BatchTimeouts
+	if err := json.Unmarshal([]byte(`{"statement":"1m30s","lock":"500ms"}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := BatchTimeouts{Statement: Duration(90 * time.Second), Lock: Duration(500 * time.Millisecond)}
+	if got != want {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"statement":30}`), &got); err == nil {
+		t.Error("a number of seconds was accepted, want a duration string")
+	}
+	if err := (BatchTimeouts{Lock: Duration(-time.Second)}).Validate(); err != ErrInvalidTimeouts {
+		t.Errorf("Validate() of a negative timeout = %v, want %v", err, ErrInvalidTimeouts)
+	}
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent представляет уведомление о завершении асинхронной задачи
+type WebhookEvent struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	TaskID     string        `json:"task_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Result     CleanupResult `json:"result"`
+}
+
+// WebhookDeadLetter представляет уведомление, которое не удалось доставить
+type WebhookDeadLetter struct {
+	ID        int64           `json:"id" db:"id"`
+	EventID   string          `json:"event_id" db:"event_id"`
+	TaskID    string          `json:"task_id" db:"task_id"`
+	URL       string          `json:"url" db:"url"`
+	Payload   json.RawMessage `json:"payload" db:"payload"`
+	Attempts  int             `json:"attempts" db:"attempts"`
+	LastError string          `json:"last_error" db:"last_error"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// WebhookEventType возвращает тип события по итоговому статусу задачи
+func WebhookEventType(status string) string {
+	return "cleanup." + status
+}
@@ -0,0 +1,266 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow описывает повторяющийся интервал времени, в который разрешено удаление данных
+type MaintenanceWindow struct {
+	Days     [7]bool        // Дни недели, в которые окно открывается (индекс - time.Weekday)
+	Start    time.Duration  // Время открытия окна от начала суток
+	End      time.Duration  // Время закрытия окна от начала суток
+	Location *time.Location // Часовой пояс, в котором задано окно
+	raw      string
+}
+
+// MaintenanceSchedule содержит глобальные окна обслуживания и окна для отдельных таблиц
+type MaintenanceSchedule struct {
+	Global []MaintenanceWindow
+	Tables map[string][]MaintenanceWindow
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// WindowClosedError возвращается синхронной очисткой, которая пришла вне окна обслуживания
+// или которую остановило закрытие окна: клиент ждет ответа и не может ждать следующего окна
+type WindowClosedError struct {
+	Table       string
+	NextOpen    time.Time // Когда окно откроется снова; нулевое, если неизвестно
+	RowsDeleted int       // Сколько строк удалено до остановки
+	NotStarted  bool      // Запрос пришел вне окна, и очистка не начиналась
+}
+
+func (e WindowClosedError) Error() string {
+	if e.NotStarted {
+		if e.NextOpen.IsZero() {
+			return fmt.Sprintf("deletions from table %s are not allowed outside the maintenance window", e.Table)
+		}
+		return fmt.Sprintf("deletions from table %s are not allowed outside the maintenance window; next window opens at %s",
+			e.Table, e.NextOpen.UTC().Format(time.RFC3339))
+	}
+	if e.NextOpen.IsZero() {
+		return fmt.Sprintf("maintenance window for table %s closed, cleanup stopped", e.Table)
+	}
+	return fmt.Sprintf("maintenance window for table %s closed, cleanup stopped; next window opens at %s",
+		e.Table, e.NextOpen.UTC().Format(time.RFC3339))
+}
+
+// ParseMaintenanceWindow разбирает окно в формате "Mon-Fri 01:00-05:00 Europe/Moscow".
+// Дни можно перечислить через запятую или указать "*" для всех дней,
+// часовой пояс необязателен (по умолчанию UTC).
+func ParseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	w := MaintenanceWindow{Location: time.UTC, raw: strings.TrimSpace(s)}
+
+	fields := strings.Fields(s)
+	if len(fields) < 2 || len(fields) > 3 {
+		return w, fmt.Errorf("invalid maintenance window %q: expected \"<days> <HH:MM>-<HH:MM> [timezone]\"", s)
+	}
+
+	if err := w.parseDays(fields[0]); err != nil {
+		return w, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+
+	bounds := strings.SplitN(fields[1], "-", 2)
+	if len(bounds) != 2 {
+		return w, fmt.Errorf("invalid maintenance window %q: time range must be HH:MM-HH:MM", s)
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return w, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+	if w.End, err = parseTimeOfDay(bounds[1]); err != nil {
+		return w, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+	if w.Start == w.End {
+		return w, fmt.Errorf("invalid maintenance window %q: window has zero length", s)
+	}
+
+	if len(fields) == 3 {
+		loc, err := time.LoadLocation(fields[2])
+		if err != nil {
+			return w, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+		}
+		w.Location = loc
+	}
+
+	return w, nil
+}
+
+// ParseMaintenanceWindows разбирает список окон, разделенных точкой с запятой
+func ParseMaintenanceWindows(s string) ([]MaintenanceWindow, error) {
+	var windows []MaintenanceWindow
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		w, err := ParseMaintenanceWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// String возвращает исходное представление окна
+func (w MaintenanceWindow) String() string {
+	return w.raw
+}
+
+// Contains проверяет, попадает ли момент времени в окно
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	local := t.In(w.location())
+	tod := sinceMidnight(local)
+
+	if w.Start < w.End {
+		return w.Days[local.Weekday()] && tod >= w.Start && tod < w.End
+	}
+
+	// Окно переходит через полночь: хвост относится ко дню открытия
+	if w.Days[local.Weekday()] && tod >= w.Start {
+		return true
+	}
+	yesterday := local.AddDate(0, 0, -1).Weekday()
+	return w.Days[yesterday] && tod < w.End
+}
+
+// NextOpen возвращает ближайший момент открытия окна, начиная с t.
+// Если окно уже открыто, возвращается t.
+func (w MaintenanceWindow) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+
+	local := t.In(w.location())
+	for d := 0; d <= 7; d++ {
+		day := local.AddDate(0, 0, d)
+		if !w.Days[day.Weekday()] {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, w.location()).Add(w.Start)
+		if start.After(local) {
+			return start
+		}
+	}
+
+	return time.Time{}
+}
+
+// WindowsFor возвращает окна для таблицы: собственные окна таблицы имеют приоритет над глобальными.
+// Имена сравниваются с учетом схемы по умолчанию, как в TablePolicy: "users", "Users"
+// и "public.users" - одна и та же таблица.
+func (s MaintenanceSchedule) WindowsFor(tableName string) []MaintenanceWindow {
+	qualified := QualifiedTableName(tableName)
+	if windows, ok := s.Tables[qualified]; ok {
+		return windows
+	}
+	// Расписание, собранное не из конфигурации, может содержать ненормализованные ключи
+	for table, windows := range s.Tables {
+		if QualifiedTableName(table) == qualified {
+			return windows
+		}
+	}
+	return s.Global
+}
+
+// IsOpen проверяет, разрешено ли удаление из таблицы в момент t.
+// Если окна не настроены, удаление разрешено всегда.
+func (s MaintenanceSchedule) IsOpen(tableName string, t time.Time) bool {
+	windows := s.WindowsFor(tableName)
+	if len(windows) == 0 {
+		return true
+	}
+
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen возвращает ближайший момент, когда удаление из таблицы будет разрешено
+func (s MaintenanceSchedule) NextOpen(tableName string, t time.Time) time.Time {
+	windows := s.WindowsFor(tableName)
+	if len(windows) == 0 {
+		return t
+	}
+
+	var next time.Time
+	for _, w := range windows {
+		candidate := w.NextOpen(t)
+		if candidate.IsZero() {
+			continue
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	return next
+}
+
+// Вспомогательные функции
+
+func (w MaintenanceWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+func (w *MaintenanceWindow) parseDays(spec string) error {
+	spec = strings.ToLower(spec)
+	if spec == "*" || spec == "daily" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := weekdayNames[from]
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", from)
+		}
+		if !isRange {
+			w.Days[start] = true
+			continue
+		}
+		end, ok := weekdayNames[to]
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", to)
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+}
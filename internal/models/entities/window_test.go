@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceScheduleWindowsForNormalizesNames(t *testing.T) {
+	global, err := ParseMaintenanceWindow("* 01:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := ParseMaintenanceWindow("* 03:00-04:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		table string
+		want  MaintenanceWindow
+	}{
+		{"bare key, bare name", "users", "users", users},
+		{"bare key, qualified name", "users", "public.users", users},
+		{"bare key, mixed case name", "users", "Public.Users", users},
+		{"qualified key, bare name", "public.users", "users", users},
+		{"other schema", "users", "audit.users", global},
+		{"other table", "users", "orders", global},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := MaintenanceSchedule{
+				Global: []MaintenanceWindow{global},
+				Tables: map[string][]MaintenanceWindow{tt.key: {users}},
+			}
+
+			got := schedule.WindowsFor(tt.table)
+			if len(got) != 1 || got[0].String() != tt.want.String() {
+				t.Errorf("WindowsFor(%q) = %v, want [%v]", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceScheduleIsOpen(t *testing.T) {
+	w, err := ParseMaintenanceWindow("Mon-Fri 22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := MaintenanceSchedule{Tables: map[string][]MaintenanceWindow{"public.events": {w}}}
+
+	// 2024-01-05 - пятница
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 1, 5, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 6, 1, 0, 0, 0, time.UTC), true}, // хвост пятничного окна
+		{time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.IsOpen("events", tt.at); got != tt.want {
+			t.Errorf("IsOpen(events, %s) = %v, want %v", tt.at, got, tt.want)
+		}
+		// Таблицы без своих окон открыты всегда
+		if !schedule.IsOpen("orders", tt.at) {
+			t.Errorf("IsOpen(orders, %s) = false, want true", tt.at)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"data-cleaner/internal/models/entities"
+)
+
+// AuditRepository определяет интерфейс append-only хранилища журнала аудита
+type AuditRepository interface {
+	// Append добавляет запись в конец цепочки, заполняя номер и хеши
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+
+	// List возвращает записи журнала в порядке возрастания номера
+	List(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+}
+
+// AuditUseCase определяет операции чтения и проверки журнала аудита
+type AuditUseCase interface {
+	// ListEntries возвращает записи журнала по фильтру; доступно ключам с правами администратора
+	ListEntries(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+
+	// Verify проверяет непрерывность и целостность цепочки записей; доступно ключам
+	// с правами администратора
+	Verify(ctx context.Context) (*entities.AuditVerification, error)
+
+	// RecordRejected фиксирует запрос на очистку, отклоненный до обращения к сервису очистки:
+	// без действительных учетных данных или с некорректным телом. Ошибка записи не меняет
+	// ответ клиенту и только логируется.
+	RecordRejected(ctx context.Context, req entities.CleanupRequest, mode string, reason error)
+}
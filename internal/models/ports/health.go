@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"data-cleaner/internal/models/entities"
+)
+
+// HealthRepository определяет интерфейс для проверки состояния хранилища
+type HealthRepository interface {
+	// Ping проверяет доступность базы данных
+	Ping(ctx context.Context) error
+
+	// PoolStats возвращает статистику пула соединений
+	PoolStats() entities.PoolStats
+}
+
+// HealthUseCase определяет логику проверок работоспособности сервиса
+type HealthUseCase interface {
+	// Liveness проверяет, что процесс жив и способен обслуживать запросы
+	Liveness(ctx context.Context) *entities.HealthReport
+
+	// Readiness проверяет готовность сервиса, включая доступность зависимостей
+	Readiness(ctx context.Context) *entities.HealthReport
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"time"
+)
+
+// CleanerMetrics определяет интерфейс для сбора метрик операций очистки
+type CleanerMetrics interface {
+	// ObserveBatch фиксирует удаление пакета: количество строк и длительность
+	ObserveBatch(tableName string, deleted int, duration time.Duration)
+
+	// IncLockFailures увеличивает счетчик неудачных попыток захвата блокировки
+	IncLockFailures(tableName string)
+
+	// IncBatchRetries увеличивает счетчик повторов пакетов после временных ошибок
+	IncBatchRetries(tableName string)
+
+	// AddRunningTasks изменяет количество выполняемых задач
+	AddRunningTasks(delta int)
+
+	// AddQueuedTasks изменяет количество задач, ожидающих запуска
+	AddQueuedTasks(delta int)
+}
@@ -2,17 +2,67 @@ package ports
 
 import (
 	"context"
+	"data-cleaner/internal/models/entities"
 	"time"
 )
 
 // CleanerRepository определяет интерфейс для доступа к данным
 type CleanerRepository interface {
-	// DeleteBatch удаляет пакет старых записей из указанной таблицы
-	DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int) (int, error)
+	// DeleteBatch удаляет пакет старых записей из указанной таблицы, начиная после курсора.
+	// Таймауты применяются к транзакции пакета в той мере, в какой их поддерживает СУБД.
+	DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (entities.BatchResult, error)
+
+	// TruncateIfExpired очищает таблицу командой TRUNCATE, если под блокировкой подтверждено,
+	// что все ее записи старше указанной даты. Иначе таблица не изменяется, а причина
+	// возвращается в результате.
+	TruncateIfExpired(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (entities.TruncateResult, error)
 
 	// TryAcquireLock пытается получить блокировку для таблицы
 	TryAcquireLock(ctx context.Context, tableName string) (bool, func(), error)
 
+	// IsLocked сообщает, удерживает ли кто-либо блокировку таблицы, не захватывая ее
+	IsLocked(ctx context.Context, tableName string) (bool, error)
+
+	// CountRows возвращает количество записей старше указанной даты
+	CountRows(ctx context.Context, tableName string, beforeDate time.Time) (int, error)
+
+	// EstimateRows оценивает количество записей старше указанной даты: точным подсчетом,
+	// если их не больше limit, иначе по статистике планировщика
+	EstimateRows(ctx context.Context, tableName string, beforeDate time.Time, limit int) (int, error)
+
 	// ValidateTable проверяет существование таблицы и наличие индекса по дате
 	ValidateTable(ctx context.Context, tableName string) error
+
+	// ListPartitions возвращает конечные секции таблицы, в которых есть записи старше
+	// указанной даты. Для несекционированной таблицы возвращается пустой список.
+	ListPartitions(ctx context.Context, tableName string, beforeDate time.Time) ([]string, error)
+
+	// ListTables возвращает таблицы с колонкой created_at
+	ListTables(ctx context.Context) ([]entities.TableInfo, error)
+}
+
+// CopySwapRepository очищает таблицу заменой: сохраняемые строки копируются в новую
+// таблицу той же структуры, которая затем подменяет исходную
+type CopySwapRepository interface {
+	// PrepareCopySwap проверяет, что таблицу можно заменить, создает новую таблицу без индексов
+	// и журнал изменений, который заполняет триггер исходной таблицы
+	PrepareCopySwap(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (entities.CopySwap, error)
+
+	// CopyBatch копирует в новую таблицу пакет сохраняемых строк после курсора;
+	// BatchResult.Deleted содержит число скопированных строк
+	CopyBatch(ctx context.Context, cs entities.CopySwap, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (entities.BatchResult, error)
+
+	// BuildShadowIndexes создает индексы и ограничения новой таблицы и собирает ее статистику
+	BuildShadowIndexes(ctx context.Context, cs entities.CopySwap) error
+
+	// ApplyDelta переносит в новую таблицу до batchSize записей журнала изменений
+	// и возвращает их число
+	ApplyDelta(ctx context.Context, cs entities.CopySwap, batchSize int, timeouts entities.BatchTimeouts) (int, error)
+
+	// SwapTables под эксклюзивной блокировкой переносит остаток журнала, права, владельца
+	// и последовательности и заменяет исходную таблицу новой
+	SwapTables(ctx context.Context, cs entities.CopySwap, timeouts entities.BatchTimeouts) error
+
+	// AbortCopySwap удаляет новую таблицу, журнал изменений и триггер
+	AbortCopySwap(ctx context.Context, cs entities.CopySwap) error
 }
@@ -15,4 +15,14 @@ type CleanerUseCase interface {
 
 	// GetCleanupStatus возвращает статус операции очистки по идентификатору
 	GetCleanupStatus(ctx context.Context, taskID string) (*entities.CleanupResult, error)
+
+	// SubscribeProgress подписывает на события хода выполнения задачи.
+	// Канал закрывается после итогового события; функция отменяет подписку.
+	SubscribeProgress(ctx context.Context, taskID string) (<-chan entities.ProgressEvent, func(), error)
+
+	// GetTaskStats возвращает количество выполняемых и ожидающих задач
+	GetTaskStats(ctx context.Context) entities.TaskStats
+
+	// ApplySettings применяет настройки очистки, изменяемые без перезапуска
+	ApplySettings(ctx context.Context, settings entities.RuntimeSettings)
 }
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+	"data-cleaner/internal/models/entities"
+)
+
+// Notifier определяет интерфейс отправки уведомлений о завершении задач
+type Notifier interface {
+	// Notify отправляет событие на глобальные адреса и, если указан, на callbackURL задачи.
+	// Доставка выполняется асинхронно.
+	Notify(ctx context.Context, event entities.WebhookEvent, callbackURL string)
+
+	// CheckCallbackURL проверяет, что на адрес из запроса можно отправлять уведомления
+	CheckCallbackURL(callbackURL string) error
+}
+
+// DeadLetterRepository определяет хранилище недоставленных уведомлений
+type DeadLetterRepository interface {
+	// Save сохраняет недоставленное уведомление
+	Save(ctx context.Context, letter *entities.WebhookDeadLetter) error
+}
@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// Заголовки, используемые для аутентификации
+const (
+	HeaderAPIKey    = "X-API-Key"
+	HeaderKeyID     = "X-Key-ID"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// Допустимое расхождение времени подписанного запроса с часами сервера
+const maxClockSkew = 5 * time.Minute
+
+// Максимальный размер тела, читаемого для проверки подписи
+const maxSignedBodySize = 1 << 20
+
+// Максимальная длина одноразового значения подписанного запроса
+const maxNonceLength = 128
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidSignature   = errors.New("invalid request signature")
+	ErrExpiredSignature   = errors.New("request timestamp is outside the allowed window")
+	ErrReplayedSignature  = errors.New("request nonce has already been used")
+)
+
+// APIKey описывает ключ доступа и его права
+type APIKey struct {
+	ID         string
+	KeyHash    []byte // SHA-256 от статического ключа
+	HMACSecret []byte // Секрет для подписи запросов
+	Scope      entities.AuthScope
+}
+
+// keyFileEntry описывает ключ в файле конфигурации
+type keyFileEntry struct {
+	ID         string `json:"id"`
+	KeySHA256  string `json:"key_sha256"`
+	HMACSecret string `json:"hmac_secret"`
+	Scope      struct {
+		Tables       []string `json:"tables"`
+		MinRetention string   `json:"min_retention"`
+		Modes        []string `json:"modes"`
+		Admin        bool     `json:"admin"`
+	} `json:"scope"`
+}
+
+// LoadKeys читает ключи доступа из JSON-файла.
+// Статические ключи хранятся только в виде SHA-256 (echo -n "$KEY" | sha256sum).
+func LoadKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keys file: %w", err)
+	}
+
+	var entries []keyFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse keys file: %w", err)
+	}
+
+	keys := make([]APIKey, 0, len(entries))
+	for _, e := range entries {
+		key, err := e.toAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", e.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (e keyFileEntry) toAPIKey() (APIKey, error) {
+	key := APIKey{
+		ID: e.ID,
+		Scope: entities.AuthScope{
+			Tables: e.Scope.Tables,
+			Modes:  e.Scope.Modes,
+			Admin:  e.Scope.Admin,
+		},
+	}
+
+	if e.ID == "" {
+		return key, errors.New("id is required")
+	}
+	if e.KeySHA256 == "" && e.HMACSecret == "" {
+		return key, errors.New("either key_sha256 or hmac_secret is required")
+	}
+
+	if e.KeySHA256 != "" {
+		hash, err := hex.DecodeString(e.KeySHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return key, errors.New("key_sha256 must be a hex-encoded SHA-256 digest")
+		}
+		key.KeyHash = hash
+	}
+	if e.HMACSecret != "" {
+		key.HMACSecret = []byte(e.HMACSecret)
+	}
+
+	if e.Scope.MinRetention != "" {
+		d, err := time.ParseDuration(e.Scope.MinRetention)
+		if err != nil {
+			return key, fmt.Errorf("invalid min_retention: %w", err)
+		}
+		key.Scope.MinRetention = d
+	}
+
+	if err := (entities.TablePolicy{Allow: key.Scope.Tables}).Validate(); err != nil {
+		return key, fmt.Errorf("scope tables: %w", err)
+	}
+
+	for _, mode := range key.Scope.Modes {
+		if mode != entities.ModeSync && mode != entities.ModeAsync && mode != entities.ModeDryRun {
+			return key, fmt.Errorf("unknown mode %q", mode)
+		}
+	}
+
+	return key, nil
+}
+
+// Authenticator проверяет учетные данные HTTP-запросов
+type Authenticator struct {
+	keys   []APIKey
+	byID   map[string]*APIKey
+	nonces *nonceCache
+	now    func() time.Time
+}
+
+// NewAuthenticator создает аутентификатор с указанным набором ключей
+func NewAuthenticator(keys []APIKey) *Authenticator {
+	a := &Authenticator{
+		keys:   keys,
+		byID:   make(map[string]*APIKey, len(keys)),
+		nonces: newNonceCache(),
+		now:    time.Now,
+	}
+	for i := range a.keys {
+		a.byID[a.keys[i].ID] = &a.keys[i]
+	}
+	return a
+}
+
+// Authenticate определяет клиента по статическому ключу или по HMAC-подписи запроса
+func (a *Authenticator) Authenticate(r *http.Request) (*entities.Principal, error) {
+	if r.Header.Get(HeaderSignature) != "" {
+		return a.authenticateSignature(r)
+	}
+
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+	}
+	if key == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	return a.AuthenticateKey(key)
+}
+
+// AuthenticateKey ищет статический ключ по его хешу
+func (a *Authenticator) AuthenticateKey(key string) (*entities.Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+
+	for i := range a.keys {
+		k := &a.keys[i]
+		if len(k.KeyHash) > 0 && subtle.ConstantTimeCompare(k.KeyHash, hash[:]) == 1 {
+			return &entities.Principal{ID: k.ID, Scope: k.Scope}, nil
+		}
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+// authenticateSignature проверяет подпись HTTP-запроса
+func (a *Authenticator) authenticateSignature(r *http.Request) (*entities.Principal, error) {
+	// Читаем тело и возвращаем его обратно для обработчика
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return a.VerifySignature(
+		r.Header.Get(HeaderKeyID),
+		r.Header.Get(HeaderTimestamp),
+		r.Header.Get(HeaderNonce),
+		r.Header.Get(HeaderSignature),
+		r.Method, r.URL.Path, r.URL.RawQuery, body)
+}
+
+// VerifySignature проверяет подпись HMAC-SHA256 от строки
+// "METHOD\nPATH\nQUERY\nTIMESTAMP\nNONCE\nhex(SHA256(body))". Каждое одноразовое
+// значение принимается один раз, пока не истечет допустимое расхождение времени,
+// поэтому перехваченный запрос нельзя повторить.
+func (a *Authenticator) VerifySignature(keyID, timestamp, nonce, signature, method, path, query string, body []byte) (*entities.Principal, error) {
+	key, ok := a.byID[keyID]
+	if !ok || len(key.HMACSecret) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	now := a.now()
+	issued := time.Unix(ts, 0)
+	if skew := now.Sub(issued); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, ErrExpiredSignature
+	}
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return nil, ErrInvalidSignature
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	expected := Sign(key.HMACSecret, method, path, query, ts, nonce, body)
+	if !hmac.Equal(sig, expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Одноразовое значение запоминается только после проверки подписи,
+	// иначе чужие запросы могли бы занять его заранее
+	if !a.nonces.use(key.ID, nonce, issued.Add(maxClockSkew), now) {
+		return nil, ErrReplayedSignature
+	}
+
+	return &entities.Principal{ID: key.ID, Scope: key.Scope}, nil
+}
+
+// Sign вычисляет подпись запроса; используется клиентами и при проверке.
+// query - строка запроса без "?" в том виде, в котором она передается в URL.
+func Sign(secret []byte, method, path, query string, timestamp int64, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d\n%s\n%s", method, path, query, timestamp, nonce, hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
+
+// nonceCache хранит использованные одноразовые значения подписанных запросов до тех пор,
+// пока запрос с ними не станет просроченным
+type nonceCache struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time)}
+}
+
+// use запоминает одноразовое значение ключа до момента expires и сообщает,
+// не было ли оно использовано раньше
+func (c *nonceCache) use(keyID, nonce string, expires, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Просроченные значения удаляются не чаще раза в минуту
+	if now.Sub(c.pruned) > time.Minute {
+		for k, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, k)
+			}
+		}
+		c.pruned = now
+	}
+
+	k := keyID + "\x00" + nonce
+	if exp, ok := c.seen[k]; ok && !now.After(exp) {
+		return false
+	}
+	c.seen[k] = expires
+	return true
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateSignature(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	body := `{"table_name":"users"}`
+
+	// sign подписывает запрос так, как это делает клиент
+	sign := func(method, path, query string, ts time.Time, nonce, body string) string {
+		return hex.EncodeToString(Sign(secret, method, path, query, ts.Unix(), nonce, []byte(body)))
+	}
+
+	tests := []struct {
+		name      string
+		target    string // путь и строка запроса, отправляемые на сервер
+		timestamp time.Time
+		nonce     string
+		signature string
+		wantErr   error
+	}{
+		{
+			name:      "valid signature",
+			target:    "/api/v1/audit?table=users",
+			timestamp: now,
+			nonce:     "n-1",
+			signature: sign("POST", "/api/v1/audit", "table=users", now, "n-1", body),
+		},
+		{
+			name:      "same nonce again",
+			target:    "/api/v1/audit?table=users",
+			timestamp: now,
+			nonce:     "n-1",
+			signature: sign("POST", "/api/v1/audit", "table=users", now, "n-1", body),
+			wantErr:   ErrReplayedSignature,
+		},
+		{
+			name:      "modified query",
+			target:    "/api/v1/audit?table=orders",
+			timestamp: now,
+			nonce:     "n-2",
+			signature: sign("POST", "/api/v1/audit", "table=users", now, "n-2", body),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "query added to a signed path",
+			target:    "/api/v1/audit?limit=1000",
+			timestamp: now,
+			nonce:     "n-3",
+			signature: sign("POST", "/api/v1/audit", "", now, "n-3", body),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "missing nonce",
+			target:    "/api/v1/audit",
+			timestamp: now,
+			signature: sign("POST", "/api/v1/audit", "", now, "", body),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "expired timestamp",
+			target:    "/api/v1/audit",
+			timestamp: now.Add(-maxClockSkew - time.Second),
+			nonce:     "n-4",
+			signature: sign("POST", "/api/v1/audit", "", now.Add(-maxClockSkew-time.Second), "n-4", body),
+			wantErr:   ErrExpiredSignature,
+		},
+	}
+
+	a := NewAuthenticator([]APIKey{{ID: "ops", HMACSecret: secret}})
+	a.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.target, strings.NewReader(body))
+			r.Header.Set(HeaderKeyID, "ops")
+			r.Header.Set(HeaderTimestamp, strconv.FormatInt(tt.timestamp.Unix(), 10))
+			r.Header.Set(HeaderNonce, tt.nonce)
+			r.Header.Set(HeaderSignature, tt.signature)
+
+			principal, err := a.Authenticate(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && principal.ID != "ops" {
+				t.Errorf("principal = %q, want ops", principal.ID)
+			}
+		})
+	}
+}
+
+func TestNonceCacheForgetsExpiredNonces(t *testing.T) {
+	c := newNonceCache()
+	now := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	if !c.use("ops", "n", now.Add(maxClockSkew), now) {
+		t.Fatal("first use of a nonce was refused")
+	}
+	if c.use("ops", "n", now.Add(maxClockSkew), now.Add(time.Minute)) {
+		t.Fatal("nonce was accepted twice while its timestamp is valid")
+	}
+	if !c.use("other", "n", now.Add(maxClockSkew), now) {
+		t.Fatal("nonce of another key was refused")
+	}
+
+	later := now.Add(maxClockSkew + 2*time.Minute)
+	if !c.use("ops", "m", later.Add(maxClockSkew), later) {
+		t.Fatal("fresh nonce was refused")
+	}
+	if _, ok := c.seen["ops\x00n"]; ok {
+		t.Error("expired nonce was not pruned")
+	}
+}
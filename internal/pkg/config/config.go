@@ -1,20 +1,37 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"go.uber.org/zap/zapcore"
+
+	"data-cleaner/internal/models/entities"
 )
 
 // Config содержит настройки приложения
 type Config struct {
+	// Файл конфигурации, из которого загружены настройки (пусто, если файл не используется)
+	File string
+
 	// Настройки HTTP-сервера
 	ServerPort int
 
+	// Порт gRPC-сервера; 0 отключает gRPC
+	GRPCPort int
+
+	// Уровень логирования: debug, info, warn или error
+	LogLevel string
+
 	// Настройки базы данных
+	DBDriver   string // postgres, mysql или sqlite
+	DBPath     string // файл базы данных SQLite
 	DBHost     string
 	DBPort     int
 	DBUser     string
@@ -27,79 +44,305 @@ type Config struct {
 	DBMaxIdleConns    int
 	DBConnMaxLifetime time.Duration
 
+	// Дополнительные источники данных. Таблицы, подходящие под их шаблоны, очищаются
+	// через драйвер источника, остальные - через основную базу, в которой хранятся
+	// журнал аудита и недоставленные уведомления.
+	Datasources []Datasource
+
 	// Настройки очистки данных
 	DefaultBatchSize int
 	MaxRequestTime   time.Duration
+	BatchPause       time.Duration
+
+	// Срок хранения ключей идемпотентности асинхронных задач; 0 отключает идемпотентность
+	IdempotencyKeyTTL time.Duration
+
+	// Повторы пакетов после временных ошибок базы данных
+	MaxBatchRetries int
+	RetryBaseDelay  time.Duration
+	RetryMaxDelay   time.Duration
+
+	// Таймауты транзакции пакета по умолчанию и для отдельных таблиц
+	BatchTimeouts entities.TimeoutPolicy
+
+	// Предел параллельно очищаемых секций одной задачи
+	MaxParallelism int
+
+	// Окна обслуживания, в которые разрешено удаление
+	MaintenanceWindows entities.MaintenanceSchedule
+
+	// Списки разрешенных и защищенных таблиц
+	TablePolicy entities.TablePolicy
+
+	// Настройки аутентификации
+	AuthEnabled  bool
+	AuthKeysFile string
+
+	// Настройки уведомлений о завершении задач
+	WebhookURLs          []string
+	WebhookSecret        string
+	WebhookCallbacks     bool     // Принимать callback_url в асинхронных запросах
+	WebhookCallbackHosts []string // Хосты, на которые разрешены callback_url; пусто - любые по https
+	WebhookMaxAttempts   int
+	WebhookTimeout       time.Duration
+
+	// Настройки трассировки
+	TracingExporter    string // none, stdout, file или otlp
+	TracingFile        string
+	TracingSampleRatio float64
+}
+
+// Datasource описывает дополнительный источник данных со своим драйвером.
+// Настройки пула соединений у всех источников общие.
+type Datasource struct {
+	Name     string
+	Driver   string // postgres, mysql или sqlite
+	Path     string // файл базы данных SQLite
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
+	// Шаблоны таблиц источника в синтаксисе политики таблиц ("legacy.*");
+	// таблица относится к первому по имени источнику, шаблону которого соответствует
+	Tables []string
+}
+
+// Поддерживаемые СУБД
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Порты СУБД по умолчанию
+var defaultDBPorts = map[string]int{
+	DriverPostgres: 5432,
+	DriverMySQL:    3306,
 }
 
-// LoadConfig загружает конфигурацию из переменных окружения
+// Префикс переменных окружения с окнами обслуживания отдельных таблиц
+const tableWindowsEnvPrefix = "MAINTENANCE_WINDOWS_"
+
+// LoadConfig загружает конфигурацию: значения по умолчанию дополняются файлом
+// из CONFIG_FILE (YAML), а затем переменными окружения. Некорректные значения
+// не заменяются значениями по умолчанию, а возвращаются одной ошибкой со всеми проблемами.
 func LoadConfig() (*Config, error) {
 	// Загружаем .env файл, если он существует
 	_ = godotenv.Load()
 
-	config := &Config{
-		// Значения по умолчанию
+	config := defaultConfig()
+
+	// Файл конфигурации
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := config.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Переменные окружения имеют приоритет над файлом
+	env := &envLoader{}
+	env.loadInto(config)
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
+	}
+
+	// Порт по умолчанию зависит от СУБД
+	if config.DBPort == 0 {
+		config.DBPort = defaultDBPorts[config.DBDriver]
+	}
+	for i := range config.Datasources {
+		if ds := &config.Datasources[i]; ds.Port == 0 {
+			ds.Port = defaultDBPorts[ds.Driver]
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// defaultConfig возвращает конфигурацию со значениями по умолчанию
+func defaultConfig() *Config {
+	logLevel := "debug"
+	if os.Getenv("APP_ENV") == "production" {
+		logLevel = "info"
+	}
+
+	// Настройки очистки по умолчанию задает сервис
+	settings := entities.DefaultRuntimeSettings()
+
+	return &Config{
 		ServerPort:        8080,
+		GRPCPort:          9090,
+		LogLevel:          logLevel,
+		DBDriver:          DriverPostgres,
+		DBHost:            "localhost",
+		DBUser:            "postgres",
+		DBPassword:        "postgres",
+		DBName:            "postgres",
+		DBSSLMode:         "disable",
 		DBMaxOpenConns:    10,
 		DBMaxIdleConns:    5,
 		DBConnMaxLifetime: 5 * time.Minute,
-		DefaultBatchSize:  5000,
-		MaxRequestTime:    30 * time.Minute,
+		DefaultBatchSize:  settings.DefaultBatchSize,
+		MaxRequestTime:    settings.MaxRequestTime,
+		BatchPause:        settings.BatchPause,
+		IdempotencyKeyTTL: settings.IdempotencyKeyTTL,
+		MaxBatchRetries:   settings.Retry.MaxRetries,
+		RetryBaseDelay:    settings.Retry.BaseDelay,
+		RetryMaxDelay:     settings.Retry.MaxDelay,
+
+		BatchTimeouts: entities.TimeoutPolicy{
+			Default: settings.BatchTimeouts.Default,
+			Tables:  make(map[string]entities.BatchTimeouts),
+		},
+		MaxParallelism: settings.MaxParallelism,
+
+		MaintenanceWindows: entities.MaintenanceSchedule{Tables: make(map[string][]entities.MaintenanceWindow)},
+
+		AuthKeysFile: "keys.json",
+
+		WebhookMaxAttempts: 5,
+		WebhookTimeout:     10 * time.Second,
+
+		TracingExporter:    "none",
+		TracingFile:        "traces.json",
+		TracingSampleRatio: 1,
 	}
+}
 
-	// Сервер
-	if port := os.Getenv("SERVER_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			config.ServerPort = p
+// Validate проверяет согласованность настроек и возвращает все найденные ошибки
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
 		}
 	}
 
-	// База данных
-	config.DBHost = getEnv("DB_HOST", "localhost")
-	if port := os.Getenv("DB_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			config.DBPort = p
-		} else {
-			config.DBPort = 5432 // По умолчанию
+	check(c.ServerPort > 0 && c.ServerPort <= 65535, "server.port", "must be between 1 and 65535, got %d", c.ServerPort)
+	check(c.GRPCPort >= 0 && c.GRPCPort <= 65535, "server.grpc_port", "must be between 0 and 65535, got %d", c.GRPCPort)
+	check(c.GRPCPort == 0 || c.GRPCPort != c.ServerPort, "server.grpc_port", "must differ from server.port")
+
+	_, err := zapcore.ParseLevel(c.LogLevel)
+	check(err == nil, "log.level", "unknown level %q", c.LogLevel)
+
+	// checkConnection проверяет параметры подключения основной базы или источника данных
+	checkConnection := func(section, driver, path, host string, port int, name string) {
+		switch driver {
+		case DriverPostgres, DriverMySQL:
+			check(host != "", section+".host", "must not be empty")
+			check(port > 0 && port <= 65535, section+".port", "must be between 1 and 65535, got %d", port)
+			check(name != "", section+".name", "must not be empty")
+		case DriverSQLite:
+			check(path != "", section+".path", "is required for sqlite")
+		default:
+			check(false, section+".driver", "must be one of postgres, mysql, sqlite; got %q", driver)
 		}
-	} else {
-		config.DBPort = 5432
 	}
-	config.DBUser = getEnv("DB_USER", "postgres")
-	config.DBPassword = getEnv("DB_PASSWORD", "postgres")
-	config.DBName = getEnv("DB_NAME", "postgres")
-	config.DBSSLMode = getEnv("DB_SSL_MODE", "disable")
 
-	// Пул соединений
-	if val := os.Getenv("DB_MAX_OPEN_CONNS"); val != "" {
-		if p, err := strconv.Atoi(val); err == nil {
-			config.DBMaxOpenConns = p
+	checkConnection("database", c.DBDriver, c.DBPath, c.DBHost, c.DBPort, c.DBName)
+	seen := make(map[string]bool, len(c.Datasources))
+	for _, ds := range c.Datasources {
+		section := "datasources." + ds.Name
+		check(ds.Name != "", "datasources", "datasource name must not be empty")
+		check(!seen[ds.Name], section, "is defined more than once")
+		seen[ds.Name] = true
+
+		checkConnection(section, ds.Driver, ds.Path, ds.Host, ds.Port, ds.DBName)
+		check(len(ds.Tables) > 0, section+".tables", "must list at least one table pattern")
+		if err := (entities.TablePolicy{Allow: ds.Tables}).Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s.tables: %w", section, err))
 		}
 	}
-	if val := os.Getenv("DB_MAX_IDLE_CONNS"); val != "" {
-		if p, err := strconv.Atoi(val); err == nil {
-			config.DBMaxIdleConns = p
-		}
+	check(c.DBMaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	check(c.DBMaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+	check(c.DBConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+
+	check(c.DefaultBatchSize > 0, "cleanup.default_batch_size", "must be positive, got %d", c.DefaultBatchSize)
+	check(c.MaxRequestTime > 0, "cleanup.max_request_time", "must be positive")
+	check(c.BatchPause >= 0, "cleanup.batch_pause", "must not be negative")
+	check(c.IdempotencyKeyTTL >= 0, "cleanup.idempotency_key_ttl", "must not be negative")
+	check(c.MaxBatchRetries >= 0, "cleanup.max_batch_retries", "must not be negative")
+	check(c.RetryBaseDelay >= 0, "cleanup.retry_base_delay", "must not be negative")
+	check(c.RetryMaxDelay >= c.RetryBaseDelay, "cleanup.retry_max_delay", "must not be less than cleanup.retry_base_delay")
+	check(c.BatchTimeouts.Default.Validate() == nil, "batch_timeouts", "must not be negative")
+	for table, timeouts := range c.BatchTimeouts.Tables {
+		check(timeouts.Validate() == nil, "batch_timeouts.tables."+table, "must not be negative")
 	}
-	if val := os.Getenv("DB_CONN_MAX_LIFETIME"); val != "" {
-		if d, err := time.ParseDuration(val); err == nil {
-			config.DBConnMaxLifetime = d
-		}
+	check(c.MaxParallelism >= 1, "cleanup.max_parallelism", "must be at least 1, got %d", c.MaxParallelism)
+	// Каждому исполнителю нужно свое соединение, еще одно держит блокировку таблицы
+	check(c.DBMaxOpenConns == 0 || c.MaxParallelism < c.DBMaxOpenConns,
+		"cleanup.max_parallelism", "must be less than database.max_open_conns (%d)", c.DBMaxOpenConns)
+
+	if err := c.TablePolicy.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("tables: %w", err))
 	}
 
-	// Настройки очистки
-	if val := os.Getenv("DEFAULT_BATCH_SIZE"); val != "" {
-		if p, err := strconv.Atoi(val); err == nil {
-			config.DefaultBatchSize = p
-		}
+	check(!c.AuthEnabled || c.AuthKeysFile != "", "auth.keys_file", "is required when authentication is enabled")
+
+	for _, target := range c.WebhookURLs {
+		u, err := url.Parse(target)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"webhook.urls", "%q is not an absolute http(s) URL", target)
 	}
-	if val := os.Getenv("MAX_REQUEST_TIME"); val != "" {
-		if d, err := time.ParseDuration(val); err == nil {
-			config.MaxRequestTime = d
-		}
+	// Уведомления подписываются секретом: пустой ключ позволил бы подделать их
+	check(c.WebhookSecret != "" || (len(c.WebhookURLs) == 0 && !c.WebhookCallbacks),
+		"webhook.secret", "is required when webhook.urls or webhook.callbacks are set")
+	for _, host := range c.WebhookCallbackHosts {
+		check(host != "" && !strings.ContainsAny(host, "/:"), "webhook.callback_hosts", "%q is not a host name", host)
 	}
+	check(c.WebhookMaxAttempts >= 1, "webhook.max_attempts", "must be at least 1")
+	check(c.WebhookTimeout > 0, "webhook.timeout", "must be positive")
 
-	return config, nil
+	switch c.TracingExporter {
+	case "none", "stdout", "file", "otlp":
+	default:
+		check(false, "tracing.exporter", "must be one of none, stdout, file, otlp; got %q", c.TracingExporter)
+	}
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+// RuntimeSettings возвращает настройки очистки, которые применяются без перезапуска
+func (c *Config) RuntimeSettings() entities.RuntimeSettings {
+	return entities.RuntimeSettings{
+		DefaultBatchSize:  c.DefaultBatchSize,
+		MaxRequestTime:    c.MaxRequestTime,
+		BatchPause:        c.BatchPause,
+		IdempotencyKeyTTL: c.IdempotencyKeyTTL,
+		Retry: entities.RetryPolicy{
+			MaxRetries: c.MaxBatchRetries,
+			BaseDelay:  c.RetryBaseDelay,
+			MaxDelay:   c.RetryMaxDelay,
+		},
+		BatchTimeouts:      c.BatchTimeouts,
+		MaxParallelism:     c.MaxParallelism,
+		TablePolicy:        c.TablePolicy,
+		MaintenanceWindows: c.MaintenanceWindows,
+	}
+}
+
+// ForDatasource возвращает копию конфигурации, в которой параметры подключения
+// основной базы заменены параметрами источника данных
+func (c *Config) ForDatasource(ds Datasource) *Config {
+	dsConfig := *c
+	dsConfig.DBDriver = ds.Driver
+	dsConfig.DBPath = ds.Path
+	dsConfig.DBHost = ds.Host
+	dsConfig.DBPort = ds.Port
+	dsConfig.DBUser = ds.User
+	dsConfig.DBPassword = ds.Password
+	dsConfig.DBName = ds.DBName
+	dsConfig.DBSSLMode = ds.SSLMode
+	dsConfig.Datasources = nil
+	return &dsConfig
 }
 
 // GetDBConnString возвращает строку подключения к PostgreSQL
@@ -110,10 +353,161 @@ func (c *Config) GetDBConnString() string {
 	)
 }
 
-// Вспомогательная функция для получения переменной окружения с значением по умолчанию
-func getEnv(key, defaultValue string) string {
+// envLoader читает переменные окружения, накапливая ошибки разбора
+type envLoader struct {
+	errs []error
+}
+
+// loadInto переопределяет настройки заданными переменными окружения
+func (l *envLoader) loadInto(config *Config) {
+	// Сервер
+	l.int("SERVER_PORT", &config.ServerPort)
+	l.int("GRPC_PORT", &config.GRPCPort)
+	l.string("LOG_LEVEL", &config.LogLevel)
+
+	// База данных
+	l.string("DB_DRIVER", &config.DBDriver)
+	l.string("DB_PATH", &config.DBPath)
+	l.string("DB_HOST", &config.DBHost)
+	l.int("DB_PORT", &config.DBPort)
+	l.string("DB_USER", &config.DBUser)
+	l.string("DB_PASSWORD", &config.DBPassword)
+	l.string("DB_NAME", &config.DBName)
+	l.string("DB_SSL_MODE", &config.DBSSLMode)
+
+	// Пул соединений
+	l.int("DB_MAX_OPEN_CONNS", &config.DBMaxOpenConns)
+	l.int("DB_MAX_IDLE_CONNS", &config.DBMaxIdleConns)
+	l.duration("DB_CONN_MAX_LIFETIME", &config.DBConnMaxLifetime)
+
+	// Настройки очистки
+	l.int("DEFAULT_BATCH_SIZE", &config.DefaultBatchSize)
+	l.duration("MAX_REQUEST_TIME", &config.MaxRequestTime)
+	l.duration("BATCH_PAUSE", &config.BatchPause)
+	l.duration("IDEMPOTENCY_KEY_TTL", &config.IdempotencyKeyTTL)
+	l.int("MAX_BATCH_RETRIES", &config.MaxBatchRetries)
+	l.duration("RETRY_BASE_DELAY", &config.RetryBaseDelay)
+	l.duration("RETRY_MAX_DELAY", &config.RetryMaxDelay)
+	l.int("MAX_PARALLELISM", &config.MaxParallelism)
+	l.duration("BATCH_STATEMENT_TIMEOUT", (*time.Duration)(&config.BatchTimeouts.Default.Statement))
+	l.duration("BATCH_LOCK_TIMEOUT", (*time.Duration)(&config.BatchTimeouts.Default.Lock))
+	l.duration("BATCH_IDLE_IN_TRANSACTION_TIMEOUT", (*time.Duration)(&config.BatchTimeouts.Default.IdleInTransaction))
+
+	// Аутентификация
+	l.bool("AUTH_ENABLED", &config.AuthEnabled)
+	l.string("AUTH_KEYS_FILE", &config.AuthKeysFile)
+
+	// Уведомления
+	l.list("WEBHOOK_URLS", &config.WebhookURLs)
+	l.string("WEBHOOK_SECRET", &config.WebhookSecret)
+	l.bool("WEBHOOK_CALLBACKS", &config.WebhookCallbacks)
+	l.list("WEBHOOK_CALLBACK_HOSTS", &config.WebhookCallbackHosts)
+	l.int("WEBHOOK_MAX_ATTEMPTS", &config.WebhookMaxAttempts)
+	l.duration("WEBHOOK_TIMEOUT", &config.WebhookTimeout)
+
+	// Трассировка
+	l.string("TRACING_EXPORTER", &config.TracingExporter)
+	l.string("TRACING_FILE", &config.TracingFile)
+	l.float("TRACING_SAMPLE_RATIO", &config.TracingSampleRatio)
+
+	// Политика таблиц
+	l.list("TABLE_ALLOWLIST", &config.TablePolicy.Allow)
+	l.list("TABLE_DENYLIST", &config.TablePolicy.Deny)
+
+	// Окна обслуживания
+	l.maintenanceWindows(&config.MaintenanceWindows)
+}
+
+func (l *envLoader) string(key string, dst *string) {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		*dst = value
+	}
+}
+
+func (l *envLoader) int(key string, dst *int) {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %q is not an integer", key, value))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (l *envLoader) bool(key string, dst *bool) {
+	if value := os.Getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %q is not a boolean", key, value))
+			return
+		}
+		*dst = b
+	}
+}
+
+func (l *envLoader) float(key string, dst *float64) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %q is not a number", key, value))
+			return
+		}
+		*dst = f
+	}
+}
+
+func (l *envLoader) duration(key string, dst *time.Duration) {
+	if value := os.Getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %q is not a duration", key, value))
+			return
+		}
+		*dst = d
+	}
+}
+
+// list возвращает значения переменной окружения, разделенные запятой
+func (l *envLoader) list(key string, dst *[]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	*dst = values
+}
+
+// maintenanceWindows читает глобальные окна из MAINTENANCE_WINDOWS
+// и окна отдельных таблиц из MAINTENANCE_WINDOWS_<TABLE>
+func (l *envLoader) maintenanceWindows(schedule *entities.MaintenanceSchedule) {
+	if value, ok := os.LookupEnv("MAINTENANCE_WINDOWS"); ok {
+		global, err := entities.ParseMaintenanceWindows(value)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("MAINTENANCE_WINDOWS: %w", err))
+		} else {
+			schedule.Global = global
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, tableWindowsEnvPrefix) || value == "" {
+			continue
+		}
+
+		table := entities.QualifiedTableName(strings.TrimPrefix(key, tableWindowsEnvPrefix))
+		windows, err := entities.ParseMaintenanceWindows(value)
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		schedule.Tables[table] = windows
 	}
-	return defaultValue
 }
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+)
+
+func TestDefaultConfigMatchesServiceDefaults(t *testing.T) {
+	got := defaultConfig().RuntimeSettings()
+	want := entities.DefaultRuntimeSettings()
+
+	// Списки таблиц и расписание по умолчанию пусты; сравниваются остальные настройки
+	got.BatchTimeouts.Tables = nil
+	got.MaintenanceWindows = entities.MaintenanceSchedule{}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultConfig().RuntimeSettings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateRequiresWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		urls      []string
+		callbacks bool
+		secret    string
+		wantErr   bool
+	}{
+		{"webhooks disabled", nil, false, "", false},
+		{"global urls without secret", []string{"https://hooks.example.com"}, false, "", true},
+		{"callbacks without secret", nil, true, "", true},
+		{"global urls with secret", []string{"https://hooks.example.com"}, false, "s3cret", false},
+		{"callbacks with secret", nil, true, "s3cret", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			c.DBPort = defaultDBPorts[c.DBDriver]
+			c.WebhookURLs = tt.urls
+			c.WebhookCallbacks = tt.callbacks
+			c.WebhookSecret = tt.secret
+
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithReloadableKeepsRestartOnlySettings(t *testing.T) {
+	current := defaultConfig()
+	current.DBPort = defaultDBPorts[current.DBDriver]
+
+	next := *current
+	next.ServerPort = 9090
+	next.DBHost = "replica"
+	next.WebhookCallbacks = true
+	next.LogLevel = "debug"
+	next.DefaultBatchSize = current.DefaultBatchSize * 2
+	next.MaxParallelism = 1
+
+	applied := withReloadable(current, &next)
+
+	if applied.ServerPort != current.ServerPort || applied.DBHost != current.DBHost || applied.WebhookCallbacks {
+		t.Errorf("restart-only settings were applied: port %d, host %q, callbacks %v",
+			applied.ServerPort, applied.DBHost, applied.WebhookCallbacks)
+	}
+	if applied.LogLevel != "debug" || !reflect.DeepEqual(applied.RuntimeSettings(), next.RuntimeSettings()) {
+		t.Errorf("reloadable settings were not applied: log level %q, runtime %+v", applied.LogLevel, applied.RuntimeSettings())
+	}
+
+	// Пропущенные изменения обнаруживаются снова при следующей перезагрузке
+	want := []string{"server.port", "database", "webhook"}
+	if got := restartRequiredChanges(applied, &next); !reflect.DeepEqual(got, want) {
+		t.Errorf("restartRequiredChanges() after reload = %v, want %v", got, want)
+	}
+}
+
+func TestLoadFileDatasources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+datasources:
+  legacy:
+    driver: mysql
+    host: legacy-db
+    port: 3307
+    user: cleaner
+    name: legacy
+    tables: ["legacy.*"]
+  archive:
+    driver: sqlite
+    path: /var/lib/archive.db
+    tables: ["archive_*"]
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := defaultConfig()
+	c.DBPort = defaultDBPorts[c.DBDriver]
+	if err := c.loadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Источники упорядочены по имени, SSL по умолчанию отключен
+	want := []Datasource{
+		{Name: "archive", Driver: DriverSQLite, Path: "/var/lib/archive.db", SSLMode: "disable", Tables: []string{"archive_*"}},
+		{Name: "legacy", Driver: DriverMySQL, Host: "legacy-db", Port: 3307, User: "cleaner", DBName: "legacy", SSLMode: "disable", Tables: []string{"legacy.*"}},
+	}
+	if !reflect.DeepEqual(c.Datasources, want) {
+		t.Fatalf("Datasources = %+v, want %+v", c.Datasources, want)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	ds := c.ForDatasource(c.Datasources[1])
+	if ds.DBDriver != DriverMySQL || ds.DBHost != "legacy-db" || ds.DBName != "legacy" || ds.Datasources != nil ||
+		ds.DBMaxOpenConns != c.DBMaxOpenConns {
+		t.Errorf("ForDatasource() = %+v", ds)
+	}
+
+	// Источник без таблиц и без адреса базы отклоняется
+	c.Datasources = append(c.Datasources, Datasource{Name: "broken", Driver: DriverPostgres})
+	err := c.Validate()
+	for _, field := range []string{"datasources.broken.host", "datasources.broken.name", "datasources.broken.tables"} {
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("Validate() error = %v, want a problem with %s", err, field)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// fileConfig описывает структуру YAML-файла конфигурации.
+// Незаданные поля не меняют значения по умолчанию.
+type fileConfig struct {
+	Server             serverSection                `yaml:"server"`
+	Log                logSection                   `yaml:"log"`
+	Database           databaseSection              `yaml:"database"`
+	Datasources        map[string]datasourceSection `yaml:"datasources"`
+	Cleanup            cleanupSection               `yaml:"cleanup"`
+	BatchTimeouts      batchTimeoutsSection         `yaml:"batch_timeouts"`
+	MaintenanceWindows maintenanceWindowsSection    `yaml:"maintenance_windows"`
+	Tables             tablesSection                `yaml:"tables"`
+	Auth               authSection                  `yaml:"auth"`
+	Webhook            webhookSection               `yaml:"webhook"`
+	Tracing            tracingSection               `yaml:"tracing"`
+}
+
+type serverSection struct {
+	Port     *int `yaml:"port"`
+	GRPCPort *int `yaml:"grpc_port"`
+}
+
+type logSection struct {
+	Level *string `yaml:"level"`
+}
+
+type databaseSection struct {
+	Driver          *string        `yaml:"driver"`
+	Path            *string        `yaml:"path"`
+	Host            *string        `yaml:"host"`
+	Port            *int           `yaml:"port"`
+	User            *string        `yaml:"user"`
+	Password        *string        `yaml:"password"`
+	Name            *string        `yaml:"name"`
+	SSLMode         *string        `yaml:"ssl_mode"`
+	MaxOpenConns    *int           `yaml:"max_open_conns"`
+	MaxIdleConns    *int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime *time.Duration `yaml:"conn_max_lifetime"`
+}
+
+type datasourceSection struct {
+	Driver   string   `yaml:"driver"`
+	Path     string   `yaml:"path"`
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	User     string   `yaml:"user"`
+	Password string   `yaml:"password"`
+	Name     string   `yaml:"name"`
+	SSLMode  string   `yaml:"ssl_mode"`
+	Tables   []string `yaml:"tables"`
+}
+
+type cleanupSection struct {
+	DefaultBatchSize  *int           `yaml:"default_batch_size"`
+	MaxRequestTime    *time.Duration `yaml:"max_request_time"`
+	BatchPause        *time.Duration `yaml:"batch_pause"`
+	IdempotencyKeyTTL *time.Duration `yaml:"idempotency_key_ttl"`
+	MaxBatchRetries   *int           `yaml:"max_batch_retries"`
+	RetryBaseDelay    *time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay     *time.Duration `yaml:"retry_max_delay"`
+	MaxParallelism    *int           `yaml:"max_parallelism"`
+}
+
+type timeoutsSection struct {
+	Statement         *time.Duration `yaml:"statement"`
+	Lock              *time.Duration `yaml:"lock"`
+	IdleInTransaction *time.Duration `yaml:"idle_in_transaction"`
+}
+
+// apply переносит заданные в файле таймауты в timeouts
+func (s timeoutsSection) apply(timeouts *entities.BatchTimeouts) {
+	set((*time.Duration)(&timeouts.Statement), s.Statement)
+	set((*time.Duration)(&timeouts.Lock), s.Lock)
+	set((*time.Duration)(&timeouts.IdleInTransaction), s.IdleInTransaction)
+}
+
+type batchTimeoutsSection struct {
+	timeoutsSection `yaml:",inline"`
+	Tables          map[string]timeoutsSection `yaml:"tables"`
+}
+
+type maintenanceWindowsSection struct {
+	Global []string            `yaml:"global"`
+	Tables map[string][]string `yaml:"tables"`
+}
+
+type tablesSection struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+type authSection struct {
+	Enabled  *bool   `yaml:"enabled"`
+	KeysFile *string `yaml:"keys_file"`
+}
+
+type webhookSection struct {
+	URLs          []string       `yaml:"urls"`
+	Secret        *string        `yaml:"secret"`
+	Callbacks     *bool          `yaml:"callbacks"`
+	CallbackHosts []string       `yaml:"callback_hosts"`
+	MaxAttempts   *int           `yaml:"max_attempts"`
+	Timeout       *time.Duration `yaml:"timeout"`
+}
+
+type tracingSection struct {
+	Exporter    *string  `yaml:"exporter"`
+	File        *string  `yaml:"file"`
+	SampleRatio *float64 `yaml:"sample_ratio"`
+}
+
+// loadFile применяет настройки из YAML-файла. Неизвестные ключи считаются ошибкой.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	c.File = path
+
+	set(&c.ServerPort, fc.Server.Port)
+	set(&c.GRPCPort, fc.Server.GRPCPort)
+	set(&c.LogLevel, fc.Log.Level)
+
+	set(&c.DBDriver, fc.Database.Driver)
+	set(&c.DBPath, fc.Database.Path)
+	set(&c.DBHost, fc.Database.Host)
+	set(&c.DBPort, fc.Database.Port)
+	set(&c.DBUser, fc.Database.User)
+	set(&c.DBPassword, fc.Database.Password)
+	set(&c.DBName, fc.Database.Name)
+	set(&c.DBSSLMode, fc.Database.SSLMode)
+	set(&c.DBMaxOpenConns, fc.Database.MaxOpenConns)
+	set(&c.DBMaxIdleConns, fc.Database.MaxIdleConns)
+	set(&c.DBConnMaxLifetime, fc.Database.ConnMaxLifetime)
+
+	// Источники упорядочены по имени, чтобы таблица, подходящая под шаблоны
+	// нескольких источников, всегда относилась к одному из них
+	names := make([]string, 0, len(fc.Datasources))
+	for name := range fc.Datasources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section := fc.Datasources[name]
+		if section.SSLMode == "" {
+			section.SSLMode = "disable"
+		}
+		c.Datasources = append(c.Datasources, Datasource{
+			Name:     name,
+			Driver:   section.Driver,
+			Path:     section.Path,
+			Host:     section.Host,
+			Port:     section.Port,
+			User:     section.User,
+			Password: section.Password,
+			DBName:   section.Name,
+			SSLMode:  section.SSLMode,
+			Tables:   section.Tables,
+		})
+	}
+
+	set(&c.DefaultBatchSize, fc.Cleanup.DefaultBatchSize)
+	set(&c.MaxRequestTime, fc.Cleanup.MaxRequestTime)
+	set(&c.BatchPause, fc.Cleanup.BatchPause)
+	set(&c.IdempotencyKeyTTL, fc.Cleanup.IdempotencyKeyTTL)
+	set(&c.MaxBatchRetries, fc.Cleanup.MaxBatchRetries)
+	set(&c.RetryBaseDelay, fc.Cleanup.RetryBaseDelay)
+	set(&c.RetryMaxDelay, fc.Cleanup.RetryMaxDelay)
+	set(&c.MaxParallelism, fc.Cleanup.MaxParallelism)
+
+	fc.BatchTimeouts.apply(&c.BatchTimeouts.Default)
+	for table, section := range fc.BatchTimeouts.Tables {
+		table = strings.ToLower(table)
+		timeouts := c.BatchTimeouts.Tables[table]
+		section.apply(&timeouts)
+		c.BatchTimeouts.Tables[table] = timeouts
+	}
+
+	if fc.Tables.Allow != nil {
+		c.TablePolicy.Allow = fc.Tables.Allow
+	}
+	if fc.Tables.Deny != nil {
+		c.TablePolicy.Deny = fc.Tables.Deny
+	}
+
+	set(&c.AuthEnabled, fc.Auth.Enabled)
+	set(&c.AuthKeysFile, fc.Auth.KeysFile)
+
+	if fc.Webhook.URLs != nil {
+		c.WebhookURLs = fc.Webhook.URLs
+	}
+	set(&c.WebhookSecret, fc.Webhook.Secret)
+	set(&c.WebhookCallbacks, fc.Webhook.Callbacks)
+	if fc.Webhook.CallbackHosts != nil {
+		c.WebhookCallbackHosts = fc.Webhook.CallbackHosts
+	}
+	set(&c.WebhookMaxAttempts, fc.Webhook.MaxAttempts)
+	set(&c.WebhookTimeout, fc.Webhook.Timeout)
+
+	set(&c.TracingExporter, fc.Tracing.Exporter)
+	set(&c.TracingFile, fc.Tracing.File)
+	set(&c.TracingSampleRatio, fc.Tracing.SampleRatio)
+
+	// Окна обслуживания
+	var errs []error
+	for _, spec := range fc.MaintenanceWindows.Global {
+		w, err := entities.ParseMaintenanceWindow(spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("maintenance_windows.global: %w", err))
+			continue
+		}
+		c.MaintenanceWindows.Global = append(c.MaintenanceWindows.Global, w)
+	}
+	for table, specs := range fc.MaintenanceWindows.Tables {
+		table = entities.QualifiedTableName(table)
+		for _, spec := range specs {
+			w, err := entities.ParseMaintenanceWindow(spec)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("maintenance_windows.tables.%s: %w", table, err))
+				continue
+			}
+			c.MaintenanceWindows.Tables[table] = append(c.MaintenanceWindows.Tables[table], w)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// set присваивает значение, если оно задано в файле
+func set[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
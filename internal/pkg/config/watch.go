@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// Задержка перед перечитыванием файла, чтобы дождаться окончания записи
+const reloadDebounce = 500 * time.Millisecond
+
+// Watch перечитывает конфигурацию по SIGHUP и при изменении файла конфигурации
+// и передает в apply действующую конфигурацию с обновленными настройками, которые
+// применяются без перезапуска. Если конфигурация некорректна, ошибка логируется
+// и продолжают действовать прежние настройки. Изменения настроек, которые требуют
+// перезапуска, не переносятся и логируются при каждой перезагрузке, пока процесс
+// не перезапущен. Блокируется до отмены контекста.
+func Watch(ctx context.Context, current *Config, logger *zap.Logger, apply func(*Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Следим за каталогом, а не за файлом: редакторы и Kubernetes ConfigMap
+	// заменяют файл переименованием
+	var fileEvents <-chan fsnotify.Event
+	if current.File != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			logger.Warn("File watching is unavailable, reload with SIGHUP", zap.Error(err))
+		} else {
+			defer watcher.Close()
+			if err := watcher.Add(filepath.Dir(current.File)); err != nil {
+				logger.Warn("File watching is unavailable, reload with SIGHUP",
+					zap.String("file", current.File), zap.Error(err))
+			} else {
+				fileEvents = watcher.Events
+			}
+		}
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	reload := func(reason string) {
+		next, err := LoadConfig()
+		if err != nil {
+			logger.Error("Configuration reload failed, keeping current settings",
+				zap.String("reason", reason), zap.Error(err))
+			return
+		}
+
+		if changed := restartRequiredChanges(current, next); len(changed) > 0 {
+			logger.Warn("Some configuration changes require a restart and were not applied",
+				zap.Strings("settings", changed))
+		}
+
+		// Новые значения проверяются вместе с настройками, которые продолжают действовать
+		applied := withReloadable(current, next)
+		if err := applied.Validate(); err != nil {
+			logger.Error("Configuration reload failed, keeping current settings",
+				zap.String("reason", reason), zap.Error(err))
+			return
+		}
+
+		logger.Info("Configuration reloaded", zap.String("reason", reason))
+		apply(applied)
+		current = applied
+	}
+
+	for {
+		select {
+		case <-hup:
+			reload("SIGHUP")
+
+		case event, ok := <-fileEvents:
+			if !ok {
+				fileEvents = nil
+				continue
+			}
+			if isConfigFileEvent(current.File, event) {
+				debounce.Reset(reloadDebounce)
+			}
+
+		case <-debounce.C:
+			reload("file changed")
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// isConfigFileEvent проверяет, затрагивает ли событие файл конфигурации.
+// В ConfigMap файл является символьной ссылкой, и меняется каталог ..data.
+func isConfigFileEvent(file string, event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	name := filepath.Base(event.Name)
+	return filepath.Clean(event.Name) == filepath.Clean(file) || name == "..data"
+}
+
+// withReloadable возвращает копию действующей конфигурации, в которую из next перенесены
+// только настройки, применяемые без перезапуска: уровень логирования и RuntimeSettings
+func withReloadable(current, next *Config) *Config {
+	applied := *current
+	applied.LogLevel = next.LogLevel
+
+	applied.DefaultBatchSize = next.DefaultBatchSize
+	applied.MaxRequestTime = next.MaxRequestTime
+	applied.BatchPause = next.BatchPause
+	applied.IdempotencyKeyTTL = next.IdempotencyKeyTTL
+	applied.MaxBatchRetries = next.MaxBatchRetries
+	applied.RetryBaseDelay = next.RetryBaseDelay
+	applied.RetryMaxDelay = next.RetryMaxDelay
+	applied.BatchTimeouts = next.BatchTimeouts
+	applied.MaxParallelism = next.MaxParallelism
+	applied.MaintenanceWindows = next.MaintenanceWindows
+	applied.TablePolicy = next.TablePolicy
+
+	return &applied
+}
+
+// restartRequiredChanges возвращает названия изменившихся настроек, которые применяются только при запуске
+func restartRequiredChanges(old, next *Config) []string {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"server.port", old.ServerPort, next.ServerPort},
+		{"server.grpc_port", old.GRPCPort, next.GRPCPort},
+		{"database", [3]string{old.DBDriver, old.DBPath, old.GetDBConnString()}, [3]string{next.DBDriver, next.DBPath, next.GetDBConnString()}},
+		{"datasources", old.Datasources, next.Datasources},
+		{"database.max_open_conns", old.DBMaxOpenConns, next.DBMaxOpenConns},
+		{"database.max_idle_conns", old.DBMaxIdleConns, next.DBMaxIdleConns},
+		{"database.conn_max_lifetime", old.DBConnMaxLifetime, next.DBConnMaxLifetime},
+		{"auth", [2]interface{}{old.AuthEnabled, old.AuthKeysFile}, [2]interface{}{next.AuthEnabled, next.AuthKeysFile}},
+		{"webhook", [6]interface{}{old.WebhookURLs, old.WebhookSecret, old.WebhookCallbacks, old.WebhookCallbackHosts, old.WebhookMaxAttempts, old.WebhookTimeout},
+			[6]interface{}{next.WebhookURLs, next.WebhookSecret, next.WebhookCallbacks, next.WebhookCallbackHosts, next.WebhookMaxAttempts, next.WebhookTimeout}},
+		{"tracing", [3]interface{}{old.TracingExporter, old.TracingFile, old.TracingSampleRatio},
+			[3]interface{}{next.TracingExporter, next.TracingFile, next.TracingSampleRatio}},
+	}
+
+	var changed []string
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.old, f.new) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
@@ -5,8 +5,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// NewLogger создает и настраивает новый логгер
-func NewLogger(isDevelopment bool) (*zap.Logger, error) {
+// NewLogger создает и настраивает новый логгер.
+// Уровень логирования можно менять во время работы через level.
+func NewLogger(isDevelopment bool, level zap.AtomicLevel) (*zap.Logger, error) {
 	var config zap.Config
 
 	if isDevelopment {
@@ -19,6 +20,8 @@ func NewLogger(isDevelopment bool) (*zap.Logger, error) {
 		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	}
 
+	config.Level = level
+
 	// Настраиваем логирование стеков ошибок
 	config.DisableStacktrace = false
 
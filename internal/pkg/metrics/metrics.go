@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "data_cleaner"
+
+// Metrics содержит метрики приложения в формате Prometheus
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rowsDeleted   *prometheus.CounterVec
+	batchDuration *prometheus.HistogramVec
+	batchSize     *prometheus.HistogramVec
+	lockFailures  *prometheus.CounterVec
+	batchRetries  *prometheus.CounterVec
+	runningTasks  prometheus.Gauge
+	queuedTasks   prometheus.Gauge
+
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	httpResponseSize *prometheus.HistogramVec
+}
+
+// NewMetrics создает и регистрирует метрики приложения
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		rowsDeleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rows_deleted_total",
+			Help:      "Total number of rows deleted per table.",
+		}, []string{"table"}),
+
+		batchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "delete_batch_duration_seconds",
+			Help:      "Duration of DeleteBatch calls.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"table"}),
+
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "delete_batch_rows",
+			Help:      "Number of rows deleted by a single batch.",
+			Buckets:   prometheus.ExponentialBuckets(10, 4, 8),
+		}, []string{"table"}),
+
+		lockFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lock_acquisition_failures_total",
+			Help:      "Number of failed attempts to acquire a table lock.",
+		}, []string{"table"}),
+
+		batchRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "batch_retries_total",
+			Help:      "Number of batch retries after transient database errors.",
+		}, []string{"table"}),
+
+		runningTasks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "running_tasks",
+			Help:      "Number of cleanup tasks currently running.",
+		}),
+
+		queuedTasks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queued_tasks",
+			Help:      "Number of cleanup tasks waiting to start.",
+		}),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		httpResponseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_response_size_bytes",
+			Help:      "Size of HTTP responses.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		m.rowsDeleted,
+		m.batchDuration,
+		m.batchSize,
+		m.lockFailures,
+		m.batchRetries,
+		m.runningTasks,
+		m.queuedTasks,
+		m.httpRequests,
+		m.httpDuration,
+		m.httpResponseSize,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveBatch фиксирует удаление пакета: количество строк и длительность
+func (m *Metrics) ObserveBatch(tableName string, deleted int, duration time.Duration) {
+	m.rowsDeleted.WithLabelValues(tableName).Add(float64(deleted))
+	m.batchDuration.WithLabelValues(tableName).Observe(duration.Seconds())
+	m.batchSize.WithLabelValues(tableName).Observe(float64(deleted))
+}
+
+// IncLockFailures увеличивает счетчик неудачных попыток захвата блокировки
+func (m *Metrics) IncLockFailures(tableName string) {
+	m.lockFailures.WithLabelValues(tableName).Inc()
+}
+
+// IncBatchRetries увеличивает счетчик повторов пакетов после временных ошибок
+func (m *Metrics) IncBatchRetries(tableName string) {
+	m.batchRetries.WithLabelValues(tableName).Inc()
+}
+
+// AddRunningTasks изменяет количество выполняемых задач
+func (m *Metrics) AddRunningTasks(delta int) {
+	m.runningTasks.Add(float64(delta))
+}
+
+// AddQueuedTasks изменяет количество задач, ожидающих запуска
+func (m *Metrics) AddQueuedTasks(delta int) {
+	m.queuedTasks.Add(float64(delta))
+}
+
+// ObserveHTTPRequest фиксирует завершенный HTTP-запрос
+func (m *Metrics) ObserveHTTPRequest(method, route string, status, size int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+	m.httpResponseSize.WithLabelValues(method, route).Observe(float64(size))
+}
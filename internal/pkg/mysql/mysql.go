@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql" // Драйвер MySQL/MariaDB
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/pkg/config"
+)
+
+// Соответствие режимов SSL в стиле PostgreSQL параметру tls драйвера MySQL
+var tlsModes = map[string]string{
+	"":            "false",
+	"disable":     "false",
+	"allow":       "preferred",
+	"prefer":      "preferred",
+	"require":     "skip-verify",
+	"verify-ca":   "true",
+	"verify-full": "true",
+}
+
+// NewMySQLDB создает новое подключение к MySQL или MariaDB
+func NewMySQLDB(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*sqlx.DB, error) {
+	dsn, err := ConnString(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Создаем подключение
+	db, err := sqlx.ConnectContext(ctx, "mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Настраиваем пул соединений
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+
+	// Проверяем соединение
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Connected to MySQL",
+		zap.String("host", cfg.DBHost),
+		zap.Int("port", cfg.DBPort),
+		zap.String("database", cfg.DBName))
+
+	return db, nil
+}
+
+// ConnString возвращает строку подключения к MySQL.
+// Время читается и записывается в UTC, как и в PostgreSQL-репозитории.
+func ConnString(cfg *config.Config) (string, error) {
+	tls, ok := tlsModes[cfg.DBSSLMode]
+	if !ok {
+		return "", fmt.Errorf("unsupported ssl mode for mysql: %s", cfg.DBSSLMode)
+	}
+
+	mc := mysql.NewConfig()
+	mc.User = cfg.DBUser
+	mc.Passwd = cfg.DBPassword
+	mc.Net = "tcp"
+	mc.Addr = net.JoinHostPort(cfg.DBHost, strconv.Itoa(cfg.DBPort))
+	mc.DBName = cfg.DBName
+	mc.ParseTime = true
+	mc.Loc = time.UTC
+	mc.Params = map[string]string{"time_zone": "'+00:00'"}
+	mc.TLSConfig = tls
+
+	return mc.FormatDSN(), nil
+}
+
+// CloseDB закрывает соединение с базой данных
+func CloseDB(db *sqlx.DB, logger *zap.Logger) {
+	if err := db.Close(); err != nil {
+		logger.Error("Error closing database connection", zap.Error(err))
+	} else {
+		logger.Info("Database connection closed")
+	}
+}
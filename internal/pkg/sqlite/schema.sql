@@ -0,0 +1,62 @@
+-- Блокировки таблиц вместо advisory locks: запись удерживается владельцем
+-- и продлевается, пока идет очистка; просроченную запись может забрать другой процесс
+CREATE TABLE IF NOT EXISTS cleanup_locks (
+    table_name  TEXT PRIMARY KEY,
+    owner       TEXT NOT NULL,
+    acquired_at INTEGER NOT NULL, -- Unix-время в миллисекундах
+    expires_at  INTEGER NOT NULL
+);
+
+-- Журнал аудита операций очистки (append-only, записи связаны хешами)
+CREATE TABLE IF NOT EXISTS cleanup_audit_log (
+    seq           INTEGER PRIMARY KEY,
+    recorded_at   DATETIME NOT NULL,
+    actor         TEXT NOT NULL,
+    request_id    TEXT NOT NULL,
+    task_id       TEXT NOT NULL,
+    operation     TEXT NOT NULL,
+    table_name    TEXT NOT NULL,
+    before_date   DATETIME NOT NULL,
+    batch_size    INTEGER NOT NULL,
+    dry_run       BOOLEAN NOT NULL,
+    outcome       TEXT NOT NULL,
+    rows_deleted  INTEGER NOT NULL,
+    error_message TEXT NOT NULL,
+    parallelism    INTEGER NOT NULL DEFAULT 0,
+    batch_timeouts TEXT NOT NULL DEFAULT '{}',
+    partitions     TEXT NOT NULL DEFAULT '[]',
+    strategy       TEXT NOT NULL DEFAULT '',
+    allow_truncate BOOLEAN NOT NULL DEFAULT 0,
+    method         TEXT NOT NULL DEFAULT '',
+    prev_hash     TEXT NOT NULL,
+    hash          TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cleanup_audit_log_table ON cleanup_audit_log (table_name, seq);
+
+-- Запрещаем изменение и удаление записей
+CREATE TRIGGER IF NOT EXISTS cleanup_audit_log_no_update
+    BEFORE UPDATE ON cleanup_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'cleanup_audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS cleanup_audit_log_no_delete
+    BEFORE DELETE ON cleanup_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'cleanup_audit_log is append-only');
+END;
+
+-- Уведомления о завершении задач, которые не удалось доставить
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id   TEXT NOT NULL,
+    task_id    TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    payload    TEXT NOT NULL,
+    attempts   INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_task ON webhook_dead_letters (task_id);
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3" // Драйвер SQLite (требует сборки с CGO_ENABLED=1)
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/pkg/config"
+)
+
+// Служебные таблицы сервиса создаются при подключении, отдельные миграции не нужны
+//
+//go:embed schema.sql
+var schema string
+
+// Колонки, добавленные в служебные таблицы после их появления. CREATE TABLE IF NOT EXISTS
+// не меняет созданные ранее таблицы, поэтому недостающие колонки добавляются отдельно.
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"cleanup_audit_log", "parallelism", "INTEGER NOT NULL DEFAULT 0"},
+	{"cleanup_audit_log", "batch_timeouts", "TEXT NOT NULL DEFAULT '{}'"},
+	{"cleanup_audit_log", "partitions", "TEXT NOT NULL DEFAULT '[]'"},
+	{"cleanup_audit_log", "strategy", "TEXT NOT NULL DEFAULT ''"},
+	{"cleanup_audit_log", "allow_truncate", "BOOLEAN NOT NULL DEFAULT 0"},
+	{"cleanup_audit_log", "method", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Сколько миллисекунд ждать освобождения базы другим писателем
+const busyTimeout = 5000
+
+// NewSQLiteDB открывает файл базы данных SQLite и создает служебные таблицы
+func NewSQLiteDB(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*sqlx.DB, error) {
+	// Создаем подключение
+	db, err := sqlx.ConnectContext(ctx, "sqlite3", ConnString(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	// Настраиваем пул соединений
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create service tables: %w", err)
+	}
+	if err := addColumns(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("upgrade service tables: %w", err)
+	}
+
+	logger.Info("Opened SQLite database", zap.String("path", cfg.DBPath))
+
+	return db, nil
+}
+
+// addColumns добавляет в служебные таблицы колонки, которых в них еще нет
+func addColumns(ctx context.Context, db *sqlx.DB) error {
+	for _, c := range addedColumns {
+		var exists bool
+		err := db.GetContext(ctx, &exists,
+			"SELECT EXISTS (SELECT 1 FROM pragma_table_info(?) WHERE name = ?)", c.table, c.column)
+		if err != nil {
+			return fmt.Errorf("check column %s.%s: %w", c.table, c.column, err)
+		}
+		if exists {
+			continue
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("add column %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// ConnString возвращает DSN файла базы данных. Журнал WAL позволяет читать
+// во время удаления, а немедленные транзакции исключают взаимоблокировки писателей.
+func ConnString(cfg *config.Config) string {
+	params := url.Values{}
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout))
+	params.Set("_journal_mode", "WAL")
+	params.Set("_txlock", "immediate")
+	params.Set("_foreign_keys", "on")
+	params.Set("_loc", "UTC")
+
+	return "file:" + cfg.DBPath + "?" + params.Encode()
+}
+
+// CloseDB закрывает соединение с базой данных
+func CloseDB(db *sqlx.DB, logger *zap.Logger) {
+	if err := db.Close(); err != nil {
+		logger.Error("Error closing database connection", zap.Error(err))
+	} else {
+		logger.Info("Database connection closed")
+	}
+}
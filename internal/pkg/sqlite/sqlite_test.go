@@ -0,0 +1,56 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"data-cleaner/internal/pkg/config"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func TestNewSQLiteDBAddsMissingColumns(t *testing.T) {
+	cfg := &config.Config{DBPath: filepath.Join(t.TempDir(), "cleaner.db"), DBMaxOpenConns: 1, DBMaxIdleConns: 1}
+
+	// Журнал в том виде, в каком его создавали прежние версии, с одной записью
+	old, err := sqlx.Connect("sqlite3", ConnString(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.MustExec(`CREATE TABLE cleanup_audit_log (
+		seq INTEGER PRIMARY KEY, recorded_at DATETIME NOT NULL, actor TEXT NOT NULL,
+		request_id TEXT NOT NULL, task_id TEXT NOT NULL, operation TEXT NOT NULL,
+		table_name TEXT NOT NULL, before_date DATETIME NOT NULL, batch_size INTEGER NOT NULL,
+		dry_run BOOLEAN NOT NULL, outcome TEXT NOT NULL, rows_deleted INTEGER NOT NULL,
+		error_message TEXT NOT NULL, prev_hash TEXT NOT NULL, hash TEXT NOT NULL
+	)`)
+	old.MustExec(`INSERT INTO cleanup_audit_log VALUES
+		(1, '2024-01-01 00:00:00', 'ops', '', '', 'sync', 'events', '2024-01-01 00:00:00', 10, 0, 'started', 0, '', '', 'h')`)
+	old.Close()
+
+	// Повторное открытие ничего не меняет
+	for i := 0; i < 2; i++ {
+		db, err := NewSQLiteDB(context.Background(), cfg, zap.NewNop())
+		if err != nil {
+			t.Fatalf("NewSQLiteDB() error = %v", err)
+		}
+
+		var row struct {
+			Parallelism   int    `db:"parallelism"`
+			BatchTimeouts string `db:"batch_timeouts"`
+			Partitions    string `db:"partitions"`
+		}
+		err = db.Get(&row, "SELECT parallelism, batch_timeouts, partitions FROM cleanup_audit_log WHERE seq = 1")
+		db.Close()
+		if err != nil {
+			t.Fatalf("read added columns: %v", err)
+		}
+		if row.Parallelism != 0 || row.BatchTimeouts != "{}" || row.Partitions != "[]" {
+			t.Errorf("added columns = %+v, want defaults", row)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package version
+
+import (
+	"runtime"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// Значения подставляются при сборке через -ldflags "-X data-cleaner/internal/pkg/version.Version=..."
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Get возвращает сведения о текущей сборке
+func Get() entities.BuildInfo {
+	return entities.BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// CheckCallbackURL проверяет, что на адрес из запроса можно отправлять уведомления:
+// callbacks включены, хост входит в CallbackHosts, а без списка хостов используется https.
+// Адреса loopback, link-local и частных сетей запрещены; имена, которые разрешаются
+// в такие адреса, отклоняет клиент при подключении.
+func (d *Dispatcher) CheckCallbackURL(callbackURL string) error {
+	if !d.cfg.Callbacks {
+		return entities.ErrCallbacksDisabled
+	}
+
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return entities.ErrInvalidCallbackURL
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if len(d.cfg.CallbackHosts) > 0 {
+		if !slices.ContainsFunc(d.cfg.CallbackHosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+			return entities.ErrCallbackHostDenied
+		}
+	} else if u.Scheme != "https" {
+		return entities.ErrCallbackNotHTTPS
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return entities.ErrCallbackAddress
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && forbiddenAddr(ip) {
+		return entities.ErrCallbackAddress
+	}
+
+	return nil
+}
+
+// newCallbackClient создает клиент для адресов из запросов. Он проверяет адрес каждого
+// подключения уже после разрешения имени, не использует прокси из окружения
+// и не следует перенаправлениям.
+func newCallbackClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkDialAddress запрещает подключение к loopback, link-local и частным адресам
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("callback address %s: %w", address, err)
+	}
+	if forbiddenAddr(ip) {
+		return fmt.Errorf("callback address %s is not allowed", address)
+	}
+	return nil
+}
+
+// forbiddenAddr сообщает, что адрес ведет во внутреннюю сеть или на сам сервер
+func forbiddenAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
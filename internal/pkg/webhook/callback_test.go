@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+)
+
+func TestCheckCallbackURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		url     string
+		wantErr error
+	}{
+		{"callbacks disabled", Config{}, "https://hooks.example.com/done", entities.ErrCallbacksDisabled},
+		{"public https host", Config{Callbacks: true}, "https://hooks.example.com/done", nil},
+		{"plain http without allowlist", Config{Callbacks: true}, "http://hooks.example.com/done", entities.ErrCallbackNotHTTPS},
+		{"plain http to an allowed host", Config{Callbacks: true, CallbackHosts: []string{"Hooks.Example.com"}}, "http://hooks.example.com/done", nil},
+		{"host outside the allowlist", Config{Callbacks: true, CallbackHosts: []string{"hooks.example.com"}}, "https://evil.example.net/", entities.ErrCallbackHostDenied},
+		{"loopback address", Config{Callbacks: true}, "https://127.0.0.1:8080/", entities.ErrCallbackAddress},
+		{"ipv6 loopback", Config{Callbacks: true}, "https://[::1]/", entities.ErrCallbackAddress},
+		{"ipv4-mapped loopback", Config{Callbacks: true}, "https://[::ffff:127.0.0.1]/", entities.ErrCallbackAddress},
+		{"localhost name", Config{Callbacks: true}, "https://localhost/", entities.ErrCallbackAddress},
+		{"cloud metadata", Config{Callbacks: true}, "https://169.254.169.254/latest/meta-data", entities.ErrCallbackAddress},
+		{"private network", Config{Callbacks: true}, "https://10.0.0.5/", entities.ErrCallbackAddress},
+		{"unspecified address", Config{Callbacks: true}, "https://0.0.0.0/", entities.ErrCallbackAddress},
+		{"allowed host with a private address", Config{Callbacks: true, CallbackHosts: []string{"192.168.1.10"}}, "http://192.168.1.10/", entities.ErrCallbackAddress},
+		{"not an http url", Config{Callbacks: true}, "ftp://hooks.example.com/", entities.ErrInvalidCallbackURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDispatcher(tt.cfg, newMemoryDeadLetters(), zap.NewNop())
+			if err := d.CheckCallbackURL(tt.url); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckCallbackURL(%q) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallbackDeliveryRefusesInternalAddresses(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	// Адрес подключения проверяется после разрешения имени, поэтому имя, указывающее
+	// на loopback, отклоняется так же, как сам адрес
+	deadLetters := newMemoryDeadLetters()
+	d := NewDispatcher(Config{Secret: []byte("s"), Callbacks: true, MaxAttempts: 1, Timeout: time.Second},
+		deadLetters, zap.NewNop())
+	d.Notify(context.Background(), entities.WebhookEvent{ID: "e-1"}, srv.URL)
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d callback requests reached a loopback server, want none", n)
+	}
+	letters := deadLetters.all()
+	if len(letters) != 1 || !strings.Contains(letters[0].LastError, "is not allowed") {
+		t.Fatalf("dead letters = %+v, want one refused callback", letters)
+	}
+}
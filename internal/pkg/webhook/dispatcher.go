@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+)
+
+// Заголовки доставляемых уведомлений
+const (
+	HeaderEventID   = "X-Webhook-Event-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Config содержит настройки доставки уведомлений
+type Config struct {
+	Targets       []string      // Глобальные адреса, получающие все события
+	Secret        []byte        // Секрет для подписи HMAC-SHA256
+	Callbacks     bool          // Принимать адреса уведомлений из запросов
+	CallbackHosts []string      // Разрешенные хосты адресов из запросов; пусто - любые по https
+	MaxAttempts   int           // Максимальное количество попыток доставки
+	BaseBackoff   time.Duration // Задержка перед второй попыткой
+	MaxBackoff    time.Duration // Верхняя граница задержки между попытками
+	Timeout       time.Duration // Таймаут одного запроса
+}
+
+// Dispatcher доставляет подписанные уведомления с повторами и экспоненциальной задержкой
+type Dispatcher struct {
+	cfg         Config
+	client      *http.Client
+	callbacks   *http.Client // Клиент для адресов из запросов: без частных адресов и перенаправлений
+	deadLetters ports.DeadLetterRepository
+	logger      *zap.Logger
+	wg          sync.WaitGroup
+	stop        chan struct{}
+	stopOnce    sync.Once
+
+	// closed запрещает новые доставки после Close; mu упорядочивает wg.Add и wg.Wait
+	mu     sync.Mutex
+	closed bool
+}
+
+// target - адрес доставки и клиент, которым она выполняется
+type target struct {
+	url    string
+	client *http.Client
+}
+
+// errClosed сохраняется в dead letter уведомления, поступившего после Close
+var errClosed = errors.New("dispatcher is closed")
+
+// NewDispatcher создает диспетчер уведомлений
+func NewDispatcher(cfg Config, deadLetters ports.DeadLetterRepository, logger *zap.Logger) *Dispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &Dispatcher{
+		cfg:         cfg,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		callbacks:   newCallbackClient(cfg.Timeout),
+		deadLetters: deadLetters,
+		logger:      logger,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Notify отправляет событие на глобальные адреса и на callbackURL задачи. Доставка
+// прерывается отменой ctx. После Close событие не отправляется, а сразу сохраняется
+// в dead letter.
+func (d *Dispatcher) Notify(ctx context.Context, event entities.WebhookEvent, callbackURL string) {
+	targets := make([]target, 0, len(d.cfg.Targets)+1)
+	for _, url := range d.cfg.Targets {
+		targets = append(targets, target{url: url, client: d.client})
+	}
+	if callbackURL != "" {
+		targets = append(targets, target{url: callbackURL, client: d.callbacks})
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Error("Failed to encode webhook event", zap.String("event_id", event.ID), zap.Error(err))
+		return
+	}
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		for _, t := range targets {
+			d.saveDeadLetter(ctx, event, t.url, payload, 0, errClosed)
+		}
+		return
+	}
+	d.wg.Add(len(targets))
+	d.mu.Unlock()
+
+	for _, t := range targets {
+		go func(t target) {
+			defer d.wg.Done()
+			d.deliver(ctx, event, t, payload)
+		}(t)
+	}
+}
+
+// Close прекращает повторы и ждет завершения текущих доставок. Уведомления,
+// поступившие после вызова, сохраняются в dead letter без попыток доставки.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.stopOnce.Do(func() { close(d.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver выполняет попытки доставки и сохраняет событие в dead letter при неудаче
+func (d *Dispatcher) deliver(ctx context.Context, event entities.WebhookEvent, t target, payload []byte) {
+	url := t.url
+	var lastErr error
+	attempt := 0
+
+	for attempt < d.cfg.MaxAttempts {
+		attempt++
+
+		retryable, err := d.send(ctx, t.client, event, url, payload)
+		if err == nil {
+			d.logger.Info("Webhook delivered",
+				zap.String("event_id", event.ID),
+				zap.String("url", url),
+				zap.Int("attempt", attempt))
+			return
+		}
+		lastErr = err
+
+		d.logger.Warn("Webhook delivery failed",
+			zap.String("event_id", event.ID),
+			zap.String("url", url),
+			zap.Int("attempt", attempt),
+			zap.Bool("retryable", retryable),
+			zap.Error(err))
+
+		if !retryable || attempt == d.cfg.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(d.backoff(attempt)):
+		case <-d.stop:
+			lastErr = fmt.Errorf("dispatcher stopped after attempt %d: %w", attempt, err)
+			attempt = d.cfg.MaxAttempts
+		case <-ctx.Done():
+			lastErr = fmt.Errorf("delivery canceled after attempt %d: %w", attempt, err)
+			attempt = d.cfg.MaxAttempts
+		}
+	}
+
+	d.saveDeadLetter(ctx, event, url, payload, attempt, lastErr)
+}
+
+// send выполняет одну попытку доставки и сообщает, имеет ли смысл повторять запрос
+func (d *Dispatcher) send(ctx context.Context, client *http.Client, event entities.WebhookEvent, url string, payload []byte) (bool, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+hex.EncodeToString(Sign(d.cfg.Secret, timestamp, payload)))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// backoff вычисляет задержку перед следующей попыткой с полным джиттером
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// saveDeadLetter сохраняет недоставленное уведомление
+func (d *Dispatcher) saveDeadLetter(ctx context.Context, event entities.WebhookEvent, url string, payload []byte, attempts int, lastErr error) {
+	letter := &entities.WebhookDeadLetter{
+		EventID:   event.ID,
+		TaskID:    event.TaskID,
+		URL:       url,
+		Payload:   payload,
+		Attempts:  attempts,
+		LastError: lastErr.Error(),
+	}
+
+	// Dead letter сохраняется и после отмены доставки
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := d.deadLetters.Save(ctx, letter); err != nil {
+		d.logger.Error("Failed to save webhook dead letter",
+			zap.String("event_id", event.ID),
+			zap.String("url", url),
+			zap.Error(err))
+		return
+	}
+
+	d.logger.Error("Webhook moved to dead letters",
+		zap.String("event_id", event.ID),
+		zap.String("url", url),
+		zap.Int("attempts", attempts))
+}
+
+// Sign вычисляет HMAC-SHA256 от строки "<timestamp>.<payload>"
+func Sign(secret []byte, timestamp int64, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
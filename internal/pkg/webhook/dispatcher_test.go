@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// memoryDeadLetters запоминает сохраненные dead letter
+type memoryDeadLetters struct {
+	mu      sync.Mutex
+	letters []entities.WebhookDeadLetter
+	saved   chan struct{}
+}
+
+func newMemoryDeadLetters() *memoryDeadLetters {
+	return &memoryDeadLetters{saved: make(chan struct{}, 100)}
+}
+
+func (r *memoryDeadLetters) Save(_ context.Context, letter *entities.WebhookDeadLetter) error {
+	r.mu.Lock()
+	r.letters = append(r.letters, *letter)
+	r.mu.Unlock()
+	r.saved <- struct{}{}
+	return nil
+}
+
+func (r *memoryDeadLetters) all() []entities.WebhookDeadLetter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entities.WebhookDeadLetter(nil), r.letters...)
+}
+
+func TestDispatcherNotifyAfterClose(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	deadLetters := newMemoryDeadLetters()
+	d := NewDispatcher(Config{Targets: []string{srv.URL}, Secret: []byte("s"), MaxAttempts: 1, Timeout: time.Second},
+		deadLetters, zap.NewNop())
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Notify(context.Background(), entities.WebhookEvent{ID: "e-1", TaskID: "t-1"}, "")
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d requests sent after Close, want none", n)
+	}
+	letters := deadLetters.all()
+	if len(letters) != 1 || letters[0].EventID != "e-1" || letters[0].Attempts != 0 {
+		t.Fatalf("dead letters = %+v, want one for e-1 without attempts", letters)
+	}
+}
+
+func TestDispatcherNotifyRacingClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	deadLetters := newMemoryDeadLetters()
+	d := NewDispatcher(Config{Targets: []string{srv.URL}, Secret: []byte("s"), MaxAttempts: 1, Timeout: time.Second},
+		deadLetters, zap.NewNop())
+
+	// Завершающиеся задачи отправляют уведомления, пока приложение останавливается
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.Notify(context.Background(), entities.WebhookEvent{ID: "e"}, "")
+		}()
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+}
+
+func TestDispatcherStopsRetriesWhenContextIsCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	deadLetters := newMemoryDeadLetters()
+	d := NewDispatcher(Config{
+		Targets:     []string{srv.URL},
+		Secret:      []byte("s"),
+		MaxAttempts: 5,
+		BaseBackoff: time.Hour,
+		MaxBackoff:  time.Hour,
+		Timeout:     time.Second,
+	}, deadLetters, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Notify(ctx, entities.WebhookEvent{ID: "e-1"}, "")
+	cancel()
+
+	select {
+	case <-deadLetters.saved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery was not abandoned after the context was canceled")
+	}
+	letters := deadLetters.all()
+	if len(letters) != 1 || !strings.Contains(letters[0].LastError, "canceled") {
+		t.Fatalf("dead letters = %+v, want one canceled delivery", letters)
+	}
+}
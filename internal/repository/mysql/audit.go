@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Имя блокировки, сериализующей добавление записей в журнал
+const auditLockName = lockPrefix + "auditlog"
+
+// Сколько секунд ждать блокировку журнала
+const auditLockTimeout = 10
+
+type auditRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewAuditRepository создает репозиторий журнала аудита в MySQL/MariaDB
+func NewAuditRepository(db *sqlx.DB, logger *zap.Logger) ports.AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Append добавляет запись в конец цепочки под именованной блокировкой
+func (r *auditRepository) Append(ctx context.Context, entry *entities.AuditEntry) (err error) {
+	ctx, span := tracer.Start(ctx, "auditRepository.Append")
+	defer func() { endSpan(span, err) }()
+
+	// Именованная блокировка принадлежит соединению и не снимается при фиксации,
+	// поэтому блокировка и транзакция выполняются на одном выделенном соединении
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Сериализуем запись, чтобы номера шли без пропусков и цепочка не ветвилась
+	var locked sql.NullInt64
+	if err = conn.GetContext(ctx, &locked, "SELECT GET_LOCK(?, ?)", auditLockName, auditLockTimeout); err != nil {
+		return fmt.Errorf("acquire audit lock: %w", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("acquire audit lock: timed out after %ds", auditLockTimeout)
+	}
+	defer func() {
+		if _, releaseErr := conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", auditLockName); releaseErr != nil {
+			r.logger.Error("Failed to release audit lock", zap.Error(releaseErr))
+		}
+	}()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var last struct {
+		Seq  int64  `db:"seq"`
+		Hash string `db:"hash"`
+	}
+	err = tx.GetContext(ctx, &last, "SELECT seq, hash FROM cleanup_audit_log ORDER BY seq DESC LIMIT 1")
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read last audit entry: %w", err)
+	}
+
+	entry.Seq = last.Seq + 1
+	entry.PrevHash = last.Hash
+	entry.RecordedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+
+	_, err = tx.NamedExecContext(ctx, `
+		INSERT INTO cleanup_audit_log (
+			seq, recorded_at, actor, request_id, task_id, operation, table_name,
+			before_date, batch_size, dry_run, outcome, rows_deleted, error_message,
+			parallelism, batch_timeouts, partitions, strategy, allow_truncate, method,
+			prev_hash, hash
+		) VALUES (
+			:seq, :recorded_at, :actor, :request_id, :task_id, :operation, :table_name,
+			:before_date, :batch_size, :dry_run, :outcome, :rows_deleted, :error_message,
+			:parallelism, :batch_timeouts, :partitions, :strategy, :allow_truncate, :method,
+			:prev_hash, :hash
+		)
+	`, entry)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала в порядке возрастания номера
+func (r *auditRepository) List(ctx context.Context, filter entities.AuditFilter) (entries []entities.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "auditRepository.List")
+	defer func() { endSpan(span, err) }()
+
+	err = r.db.SelectContext(ctx, &entries, `
+		SELECT seq, recorded_at, actor, request_id, task_id, operation, table_name,
+		       before_date, batch_size, dry_run, outcome, rows_deleted, error_message,
+		       parallelism, batch_timeouts, partitions, strategy, allow_truncate, method,
+		       prev_hash, hash
+		FROM cleanup_audit_log
+		WHERE seq > ?
+		AND (? = '' OR table_name = ?)
+		ORDER BY seq
+		LIMIT ?
+	`, filter.AfterSeq, filter.TableName, filter.TableName, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+
+	return entries, nil
+}
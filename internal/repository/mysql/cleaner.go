@@ -0,0 +1,467 @@
+package mysql
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("data-cleaner/repository/mysql")
+
+// Префикс именованных блокировок; имя блокировки в MySQL ограничено 64 символами
+const lockPrefix = "data-cleaner:"
+
+type mysqlRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewMySQLRepository создает новый экземпляр MySQL/MariaDB репозитория
+func NewMySQLRepository(db *sqlx.DB, logger *zap.Logger) ports.CleanerRepository {
+	return &mysqlRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// DeleteBatch удаляет пакет самых старых записей после keyset-курсора (created_at, id).
+// Ключи пакета блокируются SELECT ... FOR UPDATE, чтобы получить курсор следующего пакета,
+// после чего в той же транзакции выполняется DELETE ... ORDER BY created_at, id LIMIT.
+func (r *mysqlRepository) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (result entities.BatchResult, err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.DeleteBatch", tableName,
+		attribute.Int("cleanup.batch_size", batchSize),
+		attribute.Bool("cleanup.cursor", !cursor.IsZero()))
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_deleted", result.Deleted))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return result, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Временные ошибки помечаются, чтобы сервис мог повторить пакет
+	defer func() { err = classifyError(err) }()
+
+	table := r.quoteTableName(tableName)
+
+	// Условие курсора записано без сравнения кортежей, чтобы использовался индекс по дате
+	where := "created_at < ?"
+	args := []interface{}{beforeDate.UTC()}
+	if !cursor.IsZero() {
+		where += " AND (created_at > ? OR (created_at = ? AND id > ?))"
+		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+	args = append(args, batchSize)
+
+	// Таймаут ожидания блокировки задается для сессии, поэтому настройка и удаление
+	// выполняются на одном соединении
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return result, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err = setLockWaitTimeout(ctx, conn, timeouts.Lock); err != nil {
+		return result, err
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Блокировка ключей пакета не дает вставить строки между ними до удаления,
+	// поэтому DELETE с тем же порядком и лимитом удаляет ровно эти строки
+	var keys []struct {
+		CreatedAt time.Time `db:"created_at"`
+		ID        string    `db:"id"`
+	}
+	keysQuery := fmt.Sprintf(`
+		SELECT created_at, id FROM %s
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT ?
+		FOR UPDATE
+	`, table, where)
+	if err = tx.SelectContext(ctx, &keys, keysQuery, args...); err != nil {
+		return result, fmt.Errorf("lock batch rows: %w", err)
+	}
+
+	result.Next = cursor
+	if len(keys) == 0 {
+		result.Exhausted = true
+		if err = tx.Commit(); err != nil {
+			return result, fmt.Errorf("commit transaction: %w", err)
+		}
+		return result, nil
+	}
+
+	deleteQuery := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT ?
+	`, table, where)
+	res, err := tx.ExecContext(ctx, deleteQuery, args...)
+	if err != nil {
+		return result, fmt.Errorf("execute delete query: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return result, fmt.Errorf("read affected rows: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	last := keys[len(keys)-1]
+	result.Deleted = int(affected)
+	result.Next = entities.BatchCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	result.Exhausted = len(keys) < batchSize
+
+	return result, nil
+}
+
+// CountRows возвращает количество записей старше указанной даты
+func (r *mysqlRepository) CountRows(ctx context.Context, tableName string, beforeDate time.Time) (count int, err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.CountRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_matched", count))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return 0, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE created_at < ?`, r.quoteTableName(tableName))
+	if err = r.db.GetContext(ctx, &count, query, beforeDate.UTC()); err != nil {
+		return 0, fmt.Errorf("count rows: %w", err)
+	}
+
+	return count, nil
+}
+
+// EstimateRows оценивает количество записей старше указанной даты
+func (r *mysqlRepository) EstimateRows(ctx context.Context, tableName string, beforeDate time.Time, limit int) (estimate int, err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.EstimateRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_estimated", estimate))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return 0, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Точный подсчет, ограниченный limit строками
+	query := fmt.Sprintf(`
+		SELECT count(*) FROM (
+			SELECT 1 FROM %s WHERE created_at < ? LIMIT ?
+		) AS bounded
+	`, r.quoteTableName(tableName))
+	if err = r.db.GetContext(ctx, &estimate, query, beforeDate.UTC(), limit); err != nil {
+		return 0, fmt.Errorf("bounded count: %w", err)
+	}
+	if estimate < limit {
+		return estimate, nil
+	}
+
+	// Строк больше лимита - берем оценку оптимизатора из колонки rows плана,
+	// формат которой совпадает в MySQL и MariaDB
+	query = fmt.Sprintf(`EXPLAIN SELECT 1 FROM %s WHERE created_at < ?`, r.quoteTableName(tableName))
+	rows, err := r.db.QueryxContext(ctx, query, beforeDate.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("explain query: %w", err)
+	}
+	defer rows.Close()
+
+	planned := 0
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err = rows.MapScan(row); err != nil {
+			return 0, fmt.Errorf("parse query plan: %w", err)
+		}
+		if n := toInt(row["rows"]); n > planned {
+			planned = n
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("parse query plan: %w", err)
+	}
+
+	// Оценка оптимизатора не может быть меньше уже подсчитанного
+	if planned > estimate {
+		estimate = planned
+	}
+
+	return estimate, nil
+}
+
+// TryAcquireLock пытается получить именованную блокировку GET_LOCK для таблицы.
+// Блокировка принадлежит соединению, поэтому оно удерживается до ее освобождения.
+func (r *mysqlRepository) TryAcquireLock(ctx context.Context, tableName string) (acquired bool, unlock func(), err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.TryAcquireLock", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Bool("lock.acquired", acquired))
+		endSpan(span, err)
+	}()
+
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return false, nil, fmt.Errorf("get connection: %w", err)
+	}
+
+	lockName := r.lockName(tableName)
+
+	// GET_LOCK возвращает 1 при успехе, 0 при таймауте и NULL при ошибке
+	var result sql.NullInt64
+	if err = conn.GetContext(ctx, &result, "SELECT GET_LOCK(?, 0)", lockName); err != nil {
+		conn.Close()
+		return false, nil, fmt.Errorf("acquire named lock: %w", err)
+	}
+
+	if !result.Valid || result.Int64 != 1 {
+		conn.Close()
+		return false, nil, nil
+	}
+
+	// Возвращаем функцию для освобождения блокировки
+	unlock = func() {
+		defer conn.Close()
+
+		var released sql.NullInt64
+		if err := conn.GetContext(context.Background(), &released, "SELECT RELEASE_LOCK(?)", lockName); err != nil {
+			r.logger.Error("Failed to release named lock",
+				zap.String("table", tableName),
+				zap.String("lock_name", lockName),
+				zap.Error(err))
+		}
+	}
+
+	return true, unlock, nil
+}
+
+// IsLocked проверяет именованную блокировку таблицы через IS_USED_LOCK
+func (r *mysqlRepository) IsLocked(ctx context.Context, tableName string) (locked bool, err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.IsLocked", tableName)
+	defer func() { endSpan(span, err) }()
+
+	// IS_USED_LOCK возвращает идентификатор соединения-владельца или NULL
+	var owner sql.NullInt64
+	if err := r.db.GetContext(ctx, &owner, "SELECT IS_USED_LOCK(?)", r.lockName(tableName)); err != nil {
+		return false, fmt.Errorf("check named lock: %w", err)
+	}
+
+	return owner.Valid, nil
+}
+
+// ValidateTable проверяет существование таблицы и наличие индекса по дате
+func (r *mysqlRepository) ValidateTable(ctx context.Context, tableName string) (err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.ValidateTable", tableName)
+	defer func() { endSpan(span, err) }()
+
+	if !r.isValidTableName(tableName) {
+		return entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Проверяем существование таблицы
+	schema, table := r.splitTableName(tableName)
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND TABLE_NAME = ?
+			AND TABLE_TYPE = 'BASE TABLE'
+		)
+	`, schema, table)
+	if err != nil {
+		return fmt.Errorf("check table existence: %w", err)
+	}
+
+	if !exists {
+		return entities.NotFoundError{Resource: entities.ResourceTable, Name: tableName}
+	}
+
+	// Проверяем, что created_at является первой колонкой какого-либо индекса
+	err = r.db.GetContext(ctx, &exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+			AND TABLE_NAME = ?
+			AND COLUMN_NAME = 'created_at'
+			AND SEQ_IN_INDEX = 1
+		)
+	`, schema, table)
+	if err != nil {
+		return fmt.Errorf("check index existence: %w", err)
+	}
+
+	if !exists {
+		r.logger.Warn("Table doesn't have index on created_at column, operation may be slow",
+			zap.String("table", tableName))
+	}
+
+	return nil
+}
+
+// TruncateIfExpired не поддерживается: TRUNCATE в MySQL неявно завершает транзакцию и
+// не выполняется под LOCK TABLES, поэтому проверку и очистку нельзя провести под одной
+// блокировкой. Таблица очищается пакетами.
+func (r *mysqlRepository) TruncateIfExpired(context.Context, string, time.Time, entities.BatchTimeouts) (entities.TruncateResult, error) {
+	return entities.TruncateResult{Reason: "truncate is not supported for MySQL"}, nil
+}
+
+// ListPartitions не поддерживается: секции MySQL не адресуются как отдельные таблицы,
+// поэтому секционированная таблица очищается последовательно
+func (r *mysqlRepository) ListPartitions(context.Context, string, time.Time) ([]string, error) {
+	return nil, nil
+}
+
+// ListTables возвращает таблицы текущей базы с колонкой created_at
+func (r *mysqlRepository) ListTables(ctx context.Context) (tables []entities.TableInfo, err error) {
+	ctx, span := r.startSpan(ctx, "mysqlRepository.ListTables", "")
+	defer func() { endSpan(span, err) }()
+
+	err = r.db.SelectContext(ctx, &tables, `
+		SELECT
+			t.TABLE_NAME AS name,
+			COALESCE(t.TABLE_ROWS, 0) AS estimated_rows,
+			EXISTS (
+				SELECT 1 FROM information_schema.STATISTICS s
+				WHERE s.TABLE_SCHEMA = t.TABLE_SCHEMA
+				AND s.TABLE_NAME = t.TABLE_NAME
+				AND s.COLUMN_NAME = 'created_at'
+				AND s.SEQ_IN_INDEX = 1
+			) AS has_date_index,
+			COALESCE(t.CREATE_OPTIONS, '') LIKE '%partitioned%' AS partitioned
+		FROM information_schema.TABLES t
+		JOIN information_schema.COLUMNS c
+			ON c.TABLE_SCHEMA = t.TABLE_SCHEMA
+			AND c.TABLE_NAME = t.TABLE_NAME
+			AND c.COLUMN_NAME = 'created_at'
+		WHERE t.TABLE_SCHEMA = DATABASE()
+		AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// Вспомогательные функции
+
+// startSpan начинает спан обращения к базе данных
+func (r *mysqlRepository) startSpan(ctx context.Context, name, tableName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.sql.table", tableName))
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает спан, отмечая ошибку, если она произошла
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// lockName возвращает имя блокировки для таблицы, укладывающееся в 64 символа
+func (r *mysqlRepository) lockName(tableName string) string {
+	name := lockPrefix + tableName
+	if len(name) <= 64 {
+		return name
+	}
+	sum := sha1.Sum([]byte(tableName))
+	return lockPrefix + hex.EncodeToString(sum[:])
+}
+
+// splitTableName разделяет имя таблицы на базу данных и имя таблицы.
+// Для неквалифицированного имени база данных пуста, и используется текущая.
+func (r *mysqlRepository) splitTableName(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return "", name
+}
+
+// quoteTableName экранирует имя таблицы обратными кавычками
+func (r *mysqlRepository) quoteTableName(name string) string {
+	schema, table := r.splitTableName(name)
+	if schema == "" {
+		return "`" + table + "`"
+	}
+	return "`" + schema + "`.`" + table + "`"
+}
+
+// isValidTableName проверяет, является ли имя таблицы безопасным для использования в SQL
+func (r *mysqlRepository) isValidTableName(name string) bool {
+	// Допускается не более одного разделителя базы данных
+	if name == "" || strings.Count(name, ".") > 1 || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	// Только буквы, цифры, подчеркивания и разделитель
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '_' || char == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toInt приводит значение колонки плана к целому числу
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case uint64:
+		return int(n)
+	case float64:
+		return int(n)
+	case []byte:
+		var i int
+		fmt.Sscan(string(n), &i)
+		return i
+	case string:
+		var i int
+		fmt.Sscan(n, &i)
+		return i
+	default:
+		return 0
+	}
+}
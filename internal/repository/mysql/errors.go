@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Номера ошибок MySQL/MariaDB, после которых пакет можно безопасно повторить
+var transientErrorNumbers = map[uint16]bool{
+	1040: true, // ER_CON_COUNT_ERROR: слишком много соединений
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+	1927: true, // ER_CONNECTION_KILLED (MariaDB)
+	2006: true, // CR_SERVER_GONE_ERROR
+	2013: true, // CR_SERVER_LOST
+}
+
+// classifyError помечает временные ошибки MySQL как entities.TransientError.
+// Остальные ошибки, включая отмену контекста, возвращаются без изменений и считаются фатальными.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		if myErr.Number == 1205 {
+			// Истек innodb_lock_wait_timeout: блокировка может освободиться, поэтому пакет повторяется
+			return entities.TransientError{Code: "1205", Err: entities.TimeoutError{Kind: entities.TimeoutLock, Err: err}}
+		}
+		if transientErrorNumbers[myErr.Number] {
+			return entities.TransientError{Code: strconv.Itoa(int(myErr.Number)), Err: err}
+		}
+		return err
+	}
+
+	// Соединение разорвано до или во время выполнения запроса
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr) {
+		return entities.TransientError{Err: err}
+	}
+
+	return err
+}
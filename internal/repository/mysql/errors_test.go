@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestClassifyError(t *testing.T) {
+	myError := func(number uint16) error {
+		return &mysql.MySQLError{Number: number, Message: "test"}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantTransient bool
+		wantCode      string
+	}{
+		{"too many connections", myError(1040), true, "1040"},
+		{"lock wait timeout", myError(1205), true, "1205"},
+		{"deadlock", myError(1213), true, "1213"},
+		{"connection killed", myError(1927), true, "1927"},
+		{"server gone", myError(2006), true, "2006"},
+		{"server lost", myError(2013), true, "2013"},
+		{"wrapped deadlock", fmt.Errorf("execute delete query: %w", myError(1213)), true, "1213"},
+		{"duplicate entry", myError(1062), false, ""},
+		{"unknown table", myError(1146), false, ""},
+		{"broken connection", driver.ErrBadConn, true, ""},
+		{"invalid connection", mysql.ErrInvalidConn, true, ""},
+		{"unexpected EOF", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true, ""},
+		{"network error", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true, ""},
+		{"context canceled", context.Canceled, false, ""},
+		{"context deadline", context.DeadlineExceeded, false, ""},
+		{"other error", errors.New("syntax error"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classifyError() = %v, want it to wrap %v", got, tt.err)
+			}
+			var transient entities.TransientError
+			if isTransient := errors.As(got, &transient); isTransient != tt.wantTransient {
+				t.Fatalf("transient = %v, want %v", isTransient, tt.wantTransient)
+			}
+			if tt.wantTransient && transient.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", transient.Code, tt.wantCode)
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+}
+
+func TestClassifyLockWaitTimeout(t *testing.T) {
+	got := classifyError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"})
+
+	var timeoutErr entities.TimeoutError
+	if !errors.As(got, &timeoutErr) || timeoutErr.Kind != entities.TimeoutLock {
+		t.Errorf("classifyError() = %v, want a lock timeout", got)
+	}
+	// Блокировка может освободиться, поэтому пакет повторяется
+	if !entities.IsTransient(got) {
+		t.Errorf("classifyError() = %v, want a transient error", got)
+	}
+}
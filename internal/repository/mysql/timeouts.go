@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// setLockWaitTimeout задает innodb_lock_wait_timeout для сессии соединения.
+// Соединения возвращаются в пул, поэтому без таймаута восстанавливается глобальное значение.
+// Ограничения statement и idle_in_transaction в MySQL для DELETE не поддерживаются
+// и соблюдаются только клиентским контекстом.
+func setLockWaitTimeout(ctx context.Context, conn *sqlx.Conn, timeout entities.Duration) error {
+	var err error
+	if timeout > 0 {
+		// Значение задается в целых секундах, минимум 1
+		seconds := int(math.Ceil(time.Duration(timeout).Seconds()))
+		_, err = conn.ExecContext(ctx, "SET SESSION innodb_lock_wait_timeout = ?", seconds)
+	} else {
+		_, err = conn.ExecContext(ctx, "SET SESSION innodb_lock_wait_timeout = DEFAULT")
+	}
+	if err != nil {
+		return fmt.Errorf("set innodb_lock_wait_timeout: %w", err)
+	}
+	return nil
+}
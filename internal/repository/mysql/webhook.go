@@ -0,0 +1,51 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type deadLetterRepository struct {
+	db *sqlx.DB
+}
+
+// NewDeadLetterRepository создает хранилище недоставленных уведомлений в MySQL/MariaDB
+func NewDeadLetterRepository(db *sqlx.DB) ports.DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+// Save сохраняет недоставленное уведомление
+func (r *deadLetterRepository) Save(ctx context.Context, letter *entities.WebhookDeadLetter) (err error) {
+	ctx, span := tracer.Start(ctx, "deadLetterRepository.Save")
+	defer func() { endSpan(span, err) }()
+
+	// RETURNING поддерживается не везде, поэтому перечитываем запись по ее идентификатору
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_dead_letters (event_id, task_id, url, payload, attempts, last_error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, letter.EventID, letter.TaskID, letter.URL, string(letter.Payload), letter.Attempts, letter.LastError)
+	if err != nil {
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read dead letter id: %w", err)
+	}
+
+	err = r.db.GetContext(ctx, letter, `
+		SELECT id, event_id, task_id, url, payload, attempts, last_error, created_at
+		FROM webhook_dead_letters
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return fmt.Errorf("read dead letter: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/config"
+	"data-cleaner/internal/pkg/sqlite"
+	sqliterepo "data-cleaner/internal/repository/sqlite"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// SQLite проверяется на базе в памяти; драйвер работает только при сборке с cgo
+func init() {
+	parityBackends = append(parityBackends, parityBackend{
+		name: "sqlite",
+		open: func(t *testing.T) (*sqlx.DB, ports.CleanerRepository) {
+			// Каждое соединение с :memory: открывает свою базу, поэтому оно одно
+			cfg := &config.Config{DBPath: ":memory:", DBMaxOpenConns: 1, DBMaxIdleConns: 1}
+			db, err := sqlite.NewSQLiteDB(context.Background(), cfg, zap.NewNop())
+			if err != nil {
+				t.Fatalf("open sqlite: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			return db, sqliterepo.NewSQLiteRepository(db, zap.NewNop())
+		},
+		createTable: "CREATE TABLE %s (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL)",
+	})
+}
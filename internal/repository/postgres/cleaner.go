@@ -3,17 +3,27 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"strings"
 	"time"
 
+	"data-cleaner/internal/models/entities"
 	"data-cleaner/internal/models/ports"
 
+	"github.com/jackc/pgconn"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("data-cleaner/repository/postgres")
+
 type postgresRepository struct {
 	db     *sqlx.DB
 	logger *zap.Logger
@@ -27,31 +37,65 @@ func NewPostgresRepository(db *sqlx.DB, logger *zap.Logger) ports.CleanerReposit
 	}
 }
 
-// DeleteBatch реализует удаление данных небольшими порциями
-func (r *postgresRepository) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int) (int, error) {
+// DeleteBatch реализует удаление данных небольшими порциями. Пакет выбирается после
+// keyset-курсора (created_at, id), поэтому каждый следующий пакет не просматривает
+// заново уже очищенное начало индекса.
+func (r *postgresRepository) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (result entities.BatchResult, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.DeleteBatch", tableName,
+		attribute.Int("cleanup.batch_size", batchSize),
+		attribute.Bool("cleanup.cursor", !cursor.IsZero()))
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_deleted", result.Deleted))
+		endSpan(span, err)
+	}()
+
 	// Санитизация имени таблицы
 	if !r.isValidTableName(tableName) {
-		return 0, fmt.Errorf("invalid table name: %s", tableName)
+		return result, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
 	}
 
-	// Использование CTE для эффективного удаления с минимальной блокировкой
+	// Временные ошибки помечаются, чтобы сервис мог повторить пакет
+	defer func() { err = classifyError(err) }()
+
+	// Условие курсора; created_at >= $3 позволяет использовать индекс только по дате
+	args := []interface{}{beforeDate, batchSize}
+	after := ""
+	if !cursor.IsZero() {
+		after = "AND created_at >= $3 AND (created_at, id) > ($3, $4)"
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+
+	// CTE выбирает и блокирует пакет, удаляет его и возвращает ключ последней строки.
+	// Число выбранных строк меньше размера пакета означает, что диапазон исчерпан.
 	query := fmt.Sprintf(`
 		WITH rows_to_delete AS (
-			SELECT id FROM %s
-			WHERE created_at < $1
-			ORDER BY created_at
+			SELECT id, created_at FROM %s
+			WHERE created_at < $1 %s
+			ORDER BY created_at, id
 			LIMIT $2
 			FOR UPDATE SKIP LOCKED
+		), deleted AS (
+			DELETE FROM %s
+			WHERE id IN (SELECT id FROM rows_to_delete)
+			RETURNING 1
 		)
-		DELETE FROM %s
-		WHERE id IN (SELECT id FROM rows_to_delete)
-		RETURNING id;
-	`, tableName, tableName)
+		SELECT
+			(SELECT count(*) FROM deleted) AS deleted,
+			(SELECT count(*) FROM rows_to_delete) AS selected,
+			last.created_at,
+			last.id::text
+		FROM (SELECT 1) AS one
+		LEFT JOIN (
+			SELECT created_at, id FROM rows_to_delete
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) AS last ON true;
+	`, tableName, after, tableName)
 
 	// Начинаем транзакцию с уровнем изоляции READ COMMITTED
 	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
 	if err != nil {
-		return 0, fmt.Errorf("begin transaction: %w", err)
+		return result, fmt.Errorf("begin transaction: %w", err)
 	}
 
 	defer func() {
@@ -60,50 +104,223 @@ func (r *postgresRepository) DeleteBatch(ctx context.Context, tableName string,
 		}
 	}()
 
+	// Серверные таймауты действуют только внутри транзакции пакета и освобождают
+	// соединение, даже если клиентский контекст еще не истек
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return result, err
+	}
+
 	// Выполняем запрос
-	rows, err := tx.QueryxContext(ctx, query, beforeDate, batchSize)
+	var (
+		selected    int
+		lastCreated sql.NullTime
+		lastID      sql.NullString
+	)
+	err = tx.QueryRowxContext(ctx, query, args...).Scan(&result.Deleted, &selected, &lastCreated, &lastID)
 	if err != nil {
-		return 0, fmt.Errorf("execute delete query: %w", err)
+		return result, fmt.Errorf("execute delete query: %w", err)
 	}
-	defer rows.Close()
 
-	// Считаем количество удаленных строк
-	var count int
-	for rows.Next() {
-		count++
+	// Завершаем транзакцию
+	if err = tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return 0, fmt.Errorf("process result rows: %w", err)
+	result.Next = cursor
+	if lastCreated.Valid {
+		result.Next = entities.BatchCursor{CreatedAt: lastCreated.Time, ID: lastID.String}
+	}
+	result.Exhausted = selected < batchSize
+
+	return result, nil
+}
+
+// TruncateIfExpired очищает таблицу командой TRUNCATE, если все ее записи старше beforeDate.
+// На время проверки таблица блокируется в режиме SHARE ROW EXCLUSIVE: чтение продолжается,
+// а новые записи не могут появиться между проверкой и TRUNCATE. Ожидание блокировки
+// и подсчет строк ограничены таймаутами пакета.
+// Как и любой TRUNCATE, очистка не соблюдает MVCC: транзакции REPEATABLE READ, начатые
+// до нее, увидят таблицу пустой.
+func (r *postgresRepository) TruncateIfExpired(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (result entities.TruncateResult, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.TruncateIfExpired", tableName)
+	defer func() {
+		span.SetAttributes(
+			attribute.Bool("cleanup.truncated", result.Truncated),
+			attribute.Int("cleanup.rows_deleted", result.Rows))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return result, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	defer func() { err = classifyError(err) }()
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return result, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil || !result.Truncated {
+			tx.Rollback()
+		}
+	}()
+
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return result, err
+	}
+
+	// Для секционированной таблицы блокировка распространяется на все секции
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", tableName)); err != nil {
+		return result, fmt.Errorf("lock table: %w", err)
+	}
+
+	// max(created_at) берется из индекса; строки считаются, только если таблица подходит
+	var latest sql.NullTime
+	if err = tx.GetContext(ctx, &latest, fmt.Sprintf("SELECT max(created_at) FROM %s", tableName)); err != nil {
+		return result, fmt.Errorf("find latest row: %w", err)
+	}
+	if !latest.Valid {
+		result.Reason = "table is empty"
+		return result, nil
+	}
+	if !latest.Time.Before(beforeDate) {
+		result.Reason = fmt.Sprintf("table has rows newer than before_date (latest %s)", latest.Time.UTC().Format(time.RFC3339))
+		return result, nil
+	}
+
+	if err = tx.GetContext(ctx, &result.Rows, fmt.Sprintf("SELECT count(*) FROM %s", tableName)); err != nil {
+		return result, fmt.Errorf("count rows: %w", err)
+	}
+
+	// TRUNCATE без CASCADE отклоняется, если на таблицу ссылаются внешние ключи
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("TRUNCATE %s", tableName)); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "0A000" {
+			return entities.TruncateResult{Reason: "table is referenced by a foreign key"}, nil
+		}
+		return result, fmt.Errorf("truncate table: %w", err)
 	}
 
-	// Завершаем транзакцию
 	if err = tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit transaction: %w", err)
+		return entities.TruncateResult{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	result.Truncated = true
+
+	return result, nil
+}
+
+// CountRows возвращает количество записей старше указанной даты
+func (r *postgresRepository) CountRows(ctx context.Context, tableName string, beforeDate time.Time) (count int, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.CountRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_matched", count))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return 0, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE created_at < $1`, tableName)
+	if err = r.db.GetContext(ctx, &count, query, beforeDate); err != nil {
+		return 0, fmt.Errorf("count rows: %w", err)
 	}
 
 	return count, nil
 }
 
-// TryAcquireLock пытается получить advisory lock для таблицы
-func (r *postgresRepository) TryAcquireLock(ctx context.Context, tableName string) (bool, func(), error) {
+// EstimateRows оценивает количество записей старше указанной даты
+func (r *postgresRepository) EstimateRows(ctx context.Context, tableName string, beforeDate time.Time, limit int) (estimate int, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.EstimateRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_estimated", estimate))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return 0, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Точный подсчет, ограниченный limit строками
+	query := fmt.Sprintf(`
+		SELECT count(*) FROM (
+			SELECT 1 FROM %s WHERE created_at < $1 LIMIT $2
+		) AS bounded
+	`, tableName)
+	if err = r.db.GetContext(ctx, &estimate, query, beforeDate, limit); err != nil {
+		return 0, fmt.Errorf("bounded count: %w", err)
+	}
+	if estimate < limit {
+		return estimate, nil
+	}
+
+	// Строк больше лимита - берем оценку планировщика
+	var plan []byte
+	query = fmt.Sprintf(`EXPLAIN (FORMAT JSON) SELECT 1 FROM %s WHERE created_at < $1`, tableName)
+	if err = r.db.GetContext(ctx, &plan, query, beforeDate); err != nil {
+		return 0, fmt.Errorf("explain query: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err = json.Unmarshal(plan, &explain); err != nil {
+		return 0, fmt.Errorf("parse query plan: %w", err)
+	}
+	if len(explain) == 0 {
+		return 0, fmt.Errorf("parse query plan: empty plan")
+	}
+
+	// Оценка планировщика не может быть меньше уже подсчитанного
+	if planned := int(explain[0].Plan.Rows); planned > estimate {
+		estimate = planned
+	}
+
+	return estimate, nil
+}
+
+// TryAcquireLock пытается получить advisory lock для таблицы.
+// Блокировка уровня сессии принадлежит соединению, поэтому оно удерживается до ее
+// освобождения: иначе соединение вернулось бы в пул, повторный захват на нем прошел бы
+// как реентерабельный, а освобождение на другом соединении не сняло бы блокировку.
+func (r *postgresRepository) TryAcquireLock(ctx context.Context, tableName string) (acquired bool, unlock func(), err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.TryAcquireLock", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Bool("lock.acquired", acquired))
+		endSpan(span, err)
+	}()
+
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return false, nil, fmt.Errorf("get connection: %w", err)
+	}
+
 	// Генерируем уникальный ID для блокировки на основе имени таблицы
 	lockID := r.generateLockID(tableName)
 
-	var acquired bool
-	err := r.db.GetContext(ctx, &acquired, "SELECT pg_try_advisory_lock($1)", lockID)
+	err = conn.GetContext(ctx, &acquired, "SELECT pg_try_advisory_lock($1)", lockID)
 	if err != nil {
+		conn.Close()
 		return false, nil, fmt.Errorf("acquire advisory lock: %w", err)
 	}
 
 	if !acquired {
+		conn.Close()
 		return false, nil, nil
 	}
 
 	// Возвращаем функцию для освобождения блокировки
-	unlock := func() {
+	unlock = func() {
+		defer conn.Close()
+
 		var released bool
-		err := r.db.Get(&released, "SELECT pg_advisory_unlock($1)", lockID)
+		err := conn.GetContext(context.Background(), &released, "SELECT pg_advisory_unlock($1)", lockID)
 		if err != nil {
 			r.logger.Error("Failed to release advisory lock",
 				zap.String("table", tableName),
@@ -115,33 +332,63 @@ func (r *postgresRepository) TryAcquireLock(ctx context.Context, tableName strin
 	return true, unlock, nil
 }
 
+// IsLocked ищет advisory lock таблицы в pg_locks. Ключ bigint хранится там как
+// старшая (classid) и младшая (objid) половины с objsubid = 1.
+func (r *postgresRepository) IsLocked(ctx context.Context, tableName string) (locked bool, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.IsLocked", tableName)
+	defer func() { endSpan(span, err) }()
+
+	key := uint64(r.generateLockID(tableName))
+	err = r.db.GetContext(ctx, &locked, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory'
+			  AND granted
+			  AND database = (SELECT oid FROM pg_database WHERE datname = current_database())
+			  AND classid = $1::bigint::oid
+			  AND objid = $2::bigint::oid
+			  AND objsubid = 1
+		)
+	`, int64(key>>32), int64(key&0xffffffff))
+	if err != nil {
+		return false, fmt.Errorf("check advisory lock: %w", err)
+	}
+
+	return locked, nil
+}
+
 // ValidateTable проверяет существование таблицы и наличие индекса по дате
-func (r *postgresRepository) ValidateTable(ctx context.Context, tableName string) error {
+func (r *postgresRepository) ValidateTable(ctx context.Context, tableName string) (err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.ValidateTable", tableName)
+	defer func() { endSpan(span, err) }()
+
 	// Проверяем существование таблицы
+	schema, table := r.splitTableName(tableName)
 	var exists bool
-	err := r.db.GetContext(ctx, &exists, `
+	err = r.db.GetContext(ctx, &exists, `
 		SELECT EXISTS (
 			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
-			AND table_name = $1
+			WHERE table_schema = $1 
+			AND table_name = $2
 		)
-	`, tableName)
+	`, schema, table)
 	if err != nil {
 		return fmt.Errorf("check table existence: %w", err)
 	}
 
 	if !exists {
-		return fmt.Errorf("table %s does not exist", tableName)
+		return entities.NotFoundError{Resource: entities.ResourceTable, Name: tableName}
 	}
 
 	// Проверяем наличие индекса по created_at
 	err = r.db.GetContext(ctx, &exists, `
 		SELECT EXISTS (
 			SELECT FROM pg_indexes
-			WHERE tablename = $1
+			WHERE schemaname = $1
+			AND tablename = $2
 			AND indexdef LIKE '%created_at%'
 		)
-	`, tableName)
+	`, schema, table)
 	if err != nil {
 		return fmt.Errorf("check index existence: %w", err)
 	}
@@ -154,8 +401,110 @@ func (r *postgresRepository) ValidateTable(ctx context.Context, tableName string
 	return nil
 }
 
+// ListPartitions возвращает конечные секции таблицы, в которых есть записи старше указанной даты
+func (r *postgresRepository) ListPartitions(ctx context.Context, tableName string, beforeDate time.Time) (partitions []string, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.ListPartitions", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.partitions", len(partitions)))
+		endSpan(span, err)
+	}()
+
+	if !r.isValidTableName(tableName) {
+		return nil, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Для несекционированной таблицы pg_partition_tree возвращает только ее саму
+	var leaves []string
+	err = r.db.SelectContext(ctx, &leaves, `
+		SELECT CASE WHEN n.nspname = 'public' THEN c.relname ELSE n.nspname || '.' || c.relname END
+		FROM pg_partition_tree($1::regclass) t
+		JOIN pg_class c ON c.oid = t.relid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE t.isleaf AND t.relid <> $1::regclass
+		ORDER BY 1
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("list partitions: %w", err)
+	}
+
+	for _, leaf := range leaves {
+		// Если имя секции требует кавычек, вся таблица очищается через родительскую
+		if !r.isValidTableName(leaf) {
+			r.logger.Warn("Partition name is not supported, cleaning through the parent table",
+				zap.String("table", tableName),
+				zap.String("partition", leaf))
+			return nil, nil
+		}
+
+		var eligible bool
+		err = r.db.GetContext(ctx, &eligible,
+			fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE created_at < $1)", leaf), beforeDate)
+		if err != nil {
+			return nil, fmt.Errorf("check partition %s: %w", leaf, err)
+		}
+		if eligible {
+			partitions = append(partitions, leaf)
+		}
+	}
+
+	return partitions, nil
+}
+
+// ListTables возвращает таблицы с колонкой created_at; партиции не перечисляются отдельно
+func (r *postgresRepository) ListTables(ctx context.Context) (tables []entities.TableInfo, err error) {
+	ctx, span := r.startSpan(ctx, "postgresRepository.ListTables", "")
+	defer func() { endSpan(span, err) }()
+
+	err = r.db.SelectContext(ctx, &tables, `
+		SELECT
+			CASE WHEN n.nspname = 'public' THEN c.relname ELSE n.nspname || '.' || c.relname END AS name,
+			CASE WHEN c.relkind = 'p' THEN (
+				SELECT COALESCE(sum(GREATEST(ch.reltuples, 0)), 0)
+				FROM pg_inherits inh
+				JOIN pg_class ch ON ch.oid = inh.inhrelid
+				WHERE inh.inhparent = c.oid
+			) ELSE GREATEST(c.reltuples, 0) END::bigint AS estimated_rows,
+			EXISTS (
+				SELECT FROM pg_indexes i
+				WHERE i.schemaname = n.nspname
+				AND i.tablename = c.relname
+				AND i.indexdef LIKE '%created_at%'
+			) AS has_date_index,
+			c.relkind = 'p' AS partitioned
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'created_at' AND NOT a.attisdropped
+		WHERE c.relkind IN ('r', 'p')
+		AND NOT c.relispartition
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	return tables, nil
+}
+
 // Вспомогательные функции
 
+// startSpan начинает спан обращения к базе данных
+func (r *postgresRepository) startSpan(ctx context.Context, name, tableName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.sql.table", tableName))
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает спан, отмечая ошибку, если она произошла
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // generateLockID генерирует уникальный ID для advisory lock
 func (r *postgresRepository) generateLockID(tableName string) int64 {
 	h := fnv.New64a()
@@ -164,14 +513,26 @@ func (r *postgresRepository) generateLockID(tableName string) int64 {
 	return int64(u)
 }
 
+// splitTableName разделяет имя таблицы на схему и имя внутри схемы
+func (r *postgresRepository) splitTableName(name string) (string, string) {
+	qualified := entities.QualifiedTableName(name)
+	schema, table, _ := strings.Cut(qualified, ".")
+	return schema, table
+}
+
 // isValidTableName проверяет, является ли имя таблицы безопасным для использования в SQL
 func (r *postgresRepository) isValidTableName(name string) bool {
-	// Простая проверка имени таблицы - только буквы, цифры и подчеркивания
+	// Допускается не более одного разделителя схемы
+	if strings.Count(name, ".") > 1 || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	// Простая проверка имени таблицы - только буквы, цифры, подчеркивания и разделитель схемы
 	for _, char := range name {
 		if !((char >= 'a' && char <= 'z') ||
 			(char >= 'A' && char <= 'Z') ||
 			(char >= '0' && char <= '9') ||
-			char == '_') {
+			char == '_' || char == '.') {
 			return false
 		}
 	}
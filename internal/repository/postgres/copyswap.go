@@ -0,0 +1,843 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type copySwapRepository struct {
+	postgresRepository
+}
+
+// NewCopySwapRepository создает репозиторий очистки заменой таблицы
+func NewCopySwapRepository(db *sqlx.DB, logger *zap.Logger) ports.CopySwapRepository {
+	return &copySwapRepository{postgresRepository{
+		db:     db,
+		logger: logger,
+	}}
+}
+
+// copySwapNames - имена вспомогательных объектов очистки заменой. Они строятся по хешу
+// имени таблицы: короткие, не пересекаются между таблицами и позволяют найти остатки
+// прерванной очистки.
+type copySwapNames struct {
+	schema string
+	prefix string
+}
+
+func (r *copySwapRepository) names(tableName string) copySwapNames {
+	schema, _ := r.splitTableName(tableName)
+	return copySwapNames{schema: schema, prefix: "cs_" + shortHash(tableName)}
+}
+
+func (n copySwapNames) shadow() string   { return n.schema + "." + n.prefix + "_new" }
+func (n copySwapNames) delta() string    { return n.schema + "." + n.prefix + "_delta" }
+func (n copySwapNames) progress() string { return n.schema + "." + n.prefix + "_progress" }
+func (n copySwapNames) function() string { return n.schema + "." + n.prefix + "_capture" }
+
+// Триггеры исходной таблицы: журнал изменений строк и запрет TRUNCATE во время копирования
+func (n copySwapNames) captureTrigger() string  { return n.prefix + "_capture" }
+func (n copySwapNames) truncateTrigger() string { return n.prefix + "_truncate" }
+
+// index возвращает временное имя копии индекса или ограничения исходной таблицы
+func (n copySwapNames) index(name string) string { return n.prefix + "_" + shortHash(name) }
+
+// shortHash возвращает короткий хеш строки для имен вспомогательных объектов
+func shortHash(s string) string {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// PrepareCopySwap проверяет, что таблицу можно заменить, и создает новую таблицу с теми же
+// колонками, значениями по умолчанию и CHECK-ограничениями, но без индексов, журнал
+// изменений, триггеры, которые его заполняют, и отметку скопированных строк. Строки без даты копируются сразу:
+// они не старше before_date, но не попадают в диапазон пакетного копирования.
+func (r *copySwapRepository) PrepareCopySwap(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (cs entities.CopySwap, err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.PrepareCopySwap", tableName)
+	defer func() { endSpan(span, err) }()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return cs, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	defer func() { err = classifyError(err) }()
+
+	names := r.names(tableName)
+	if err = r.checkCopySwap(ctx, tableName, names); err != nil {
+		return cs, err
+	}
+
+	var idType string
+	err = r.db.GetContext(ctx, &idType, `
+		SELECT format_type(atttypid, atttypmod) FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attname = 'id' AND NOT attisdropped
+	`, tableName)
+	if err != nil {
+		return cs, fmt.Errorf("read id column type: %w", err)
+	}
+
+	columns, err := r.copyColumns(ctx, tableName)
+	if err != nil {
+		return cs, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return cs, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// lock_timeout ограничивает ожидание писателей, которых дожидается CREATE TRIGGER
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return cs, err
+	}
+
+	// Остатки прерванной очистки; другой очистки этой таблицы нет, ее исключает advisory lock
+	if err = r.dropCopySwapObjects(ctx, tx, tableName, names); err != nil {
+		return cs, err
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL EXCLUDING INDEXES)", names.shadow(), tableName),
+		fmt.Sprintf("CREATE TABLE %s (seq bigserial PRIMARY KEY, id %s NOT NULL)", names.delta(), idType),
+		// Единственная строка с ключом последней скопированной строки; пустая до первого пакета
+		fmt.Sprintf("CREATE TABLE %s AS SELECT created_at, id FROM %s WITH NO DATA", names.progress(), tableName),
+		fmt.Sprintf("INSERT INTO %s VALUES (NULL, NULL)", names.progress()),
+		// SECURITY DEFINER: триггер срабатывает от имени писателей, у которых нет прав на журнал
+		fmt.Sprintf(`
+			CREATE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql
+			SECURITY DEFINER SET search_path = pg_catalog, pg_temp AS $fn$
+			BEGIN
+				IF TG_OP = 'TRUNCATE' THEN
+					RAISE EXCEPTION 'table is being cleaned by copy and swap';
+				END IF;
+				IF TG_OP IN ('UPDATE', 'DELETE') THEN
+					INSERT INTO %s (id) VALUES (OLD.id);
+				END IF;
+				IF TG_OP IN ('INSERT', 'UPDATE') THEN
+					INSERT INTO %s (id) VALUES (NEW.id);
+				END IF;
+				RETURN NULL;
+			END
+			$fn$
+		`, names.function(), names.delta(), names.delta()),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+			names.captureTrigger(), tableName, names.function()),
+		fmt.Sprintf("CREATE TRIGGER %s BEFORE TRUNCATE ON %s FOR EACH STATEMENT EXECUTE FUNCTION %s()",
+			names.truncateTrigger(), tableName, names.function()),
+		fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s WHERE created_at IS NULL",
+			names.shadow(), columns, columns, tableName),
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return cs, fmt.Errorf("create copy and swap objects: %w", err)
+		}
+	}
+
+	if err = commit(tx); err != nil {
+		return cs, err
+	}
+
+	return entities.CopySwap{
+		Table:      tableName,
+		Shadow:     names.shadow(),
+		DeltaLog:   names.delta(),
+		BeforeDate: beforeDate,
+	}, nil
+}
+
+// checkCopySwap отклоняет таблицы, которые нельзя заменить без потери связанных объектов
+func (r *copySwapRepository) checkCopySwap(ctx context.Context, tableName string, names copySwapNames) error {
+	var check struct {
+		Partitioned  bool `db:"partitioned"`
+		Inherited    bool `db:"inherited"`
+		Referenced   bool `db:"referenced"`
+		HasTriggers  bool `db:"has_triggers"`
+		HasViews     bool `db:"has_views"`
+		RowSecurity  bool `db:"row_security"`
+		HasIDPrimary bool `db:"has_id_primary"`
+	}
+	err := r.db.GetContext(ctx, &check, `
+		SELECT
+			c.relkind = 'p' OR c.relispartition AS partitioned,
+			EXISTS (
+				SELECT FROM pg_inherits WHERE inhrelid = c.oid OR inhparent = c.oid
+			) AS inherited,
+			EXISTS (
+				SELECT FROM pg_constraint WHERE confrelid = c.oid AND contype = 'f'
+			) AS referenced,
+			EXISTS (
+				SELECT FROM pg_trigger
+				WHERE tgrelid = c.oid AND NOT tgisinternal AND tgname NOT IN ($2, $3)
+			) AS has_triggers,
+			EXISTS (
+				SELECT FROM pg_depend d
+				JOIN pg_rewrite rw ON rw.oid = d.objid
+				WHERE d.classid = 'pg_rewrite'::regclass AND d.refobjid = c.oid AND rw.ev_class <> c.oid
+			) AS has_views,
+			c.relrowsecurity AS row_security,
+			EXISTS (
+				SELECT FROM pg_constraint con
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attname = 'id'
+				WHERE con.conrelid = c.oid AND con.contype = 'p' AND con.conkey = ARRAY[a.attnum]
+			) AS has_id_primary
+		FROM pg_class c
+		WHERE c.oid = $1::regclass
+	`, tableName, names.captureTrigger(), names.truncateTrigger())
+	if err != nil {
+		return fmt.Errorf("check table for copy and swap: %w", err)
+	}
+
+	var reason string
+	switch {
+	case check.Partitioned:
+		reason = "partitioned tables and partitions are not supported"
+	case check.Inherited:
+		reason = "tables with inheritance are not supported"
+	case check.Referenced:
+		reason = "table is referenced by a foreign key"
+	case check.HasTriggers:
+		reason = "table has triggers"
+	case check.HasViews:
+		reason = "views or rules depend on the table"
+	case check.RowSecurity:
+		reason = "table has row level security enabled"
+	case !check.HasIDPrimary:
+		reason = "table must have a primary key on the id column"
+	default:
+		return nil
+	}
+	return entities.NewDomainError(fmt.Sprintf("copy_swap is not possible for table %s: %s", tableName, reason))
+}
+
+// copyColumns возвращает список копируемых колонок; генерируемые колонки вычисляются заново
+func (r *copySwapRepository) copyColumns(ctx context.Context, tableName string) (string, error) {
+	var columns string
+	err := r.db.GetContext(ctx, &columns, `
+		SELECT string_agg(quote_ident(attname), ', ' ORDER BY attnum) FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped AND attgenerated = ''
+	`, tableName)
+	if err != nil {
+		return "", fmt.Errorf("list columns: %w", err)
+	}
+	return columns, nil
+}
+
+// CopyBatch копирует в новую таблицу пакет строк не старше before_date после keyset-курсора.
+// Ключ последней скопированной строки сохраняется в той же транзакции, и пакет начинается
+// не раньше него: повтор после COMMIT, который дошел до сервера без ответа, не копирует
+// строки второй раз.
+func (r *copySwapRepository) CopyBatch(ctx context.Context, cs entities.CopySwap, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (result entities.BatchResult, err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.CopyBatch", cs.Table,
+		attribute.Int("cleanup.batch_size", batchSize),
+		attribute.Bool("cleanup.cursor", !cursor.IsZero()))
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_copied", result.Deleted))
+		endSpan(span, err)
+	}()
+
+	defer func() { err = classifyError(err) }()
+
+	columns, err := r.copyColumns(ctx, cs.Table)
+	if err != nil {
+		return result, err
+	}
+
+	progress := r.names(cs.Table).progress()
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return result, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return result, err
+	}
+
+	// Отметка не отстает от курсора: курсор берется из результатов зафиксированных пакетов
+	var mark struct {
+		CreatedAt sql.NullTime   `db:"created_at"`
+		ID        sql.NullString `db:"id"`
+	}
+	if err = tx.GetContext(ctx, &mark, fmt.Sprintf("SELECT created_at, id::text AS id FROM %s FOR UPDATE", progress)); err != nil {
+		return result, fmt.Errorf("read copy progress: %w", err)
+	}
+	if mark.CreatedAt.Valid {
+		cursor = entities.BatchCursor{CreatedAt: mark.CreatedAt.Time, ID: mark.ID.String}
+	}
+
+	args := []interface{}{cs.BeforeDate, batchSize}
+	after := ""
+	if !cursor.IsZero() {
+		after = "AND created_at >= $3 AND (created_at, id) > ($3, $4)"
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+
+	query := fmt.Sprintf(`
+		WITH batch AS (
+			SELECT * FROM %s
+			WHERE created_at >= $1 %s
+			ORDER BY created_at, id
+			LIMIT $2
+		), copied AS (
+			INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE
+			SELECT %s FROM batch
+			RETURNING 1
+		), last AS (
+			SELECT created_at, id FROM batch
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		), marked AS (
+			UPDATE %s AS mark SET created_at = last.created_at, id = last.id
+			FROM last
+			RETURNING 1
+		)
+		SELECT
+			(SELECT count(*) FROM copied) AS copied,
+			last.created_at,
+			last.id::text
+		FROM (SELECT 1) AS one
+		LEFT JOIN last ON true
+	`, cs.Table, after, cs.Shadow, columns, columns, progress)
+
+	var (
+		lastCreated sql.NullTime
+		lastID      sql.NullString
+	)
+	if err = tx.QueryRowxContext(ctx, query, args...).Scan(&result.Deleted, &lastCreated, &lastID); err != nil {
+		return result, fmt.Errorf("execute copy query: %w", err)
+	}
+
+	// Обрыв соединения во время COMMIT остается временной ошибкой: повтор продолжит с отметки
+	if err = tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	result.Next = cursor
+	if lastCreated.Valid {
+		result.Next = entities.BatchCursor{CreatedAt: lastCreated.Time, ID: lastID.String}
+	}
+	result.Exhausted = result.Deleted < batchSize
+
+	return result, nil
+}
+
+// shadowIndex описывает индекс исходной таблицы и его копию в новой таблице
+type shadowIndex struct {
+	Name       string `db:"name"`       // Имя в исходной таблице, готовое для подстановки в SQL
+	Definition string `db:"definition"` // pg_get_indexdef
+	Constraint string `db:"constraint"` // pg_get_constraintdef, если индекс создан ограничением
+}
+
+// sourceIndexes возвращает индексы исходной таблицы
+func (r *copySwapRepository) sourceIndexes(ctx context.Context, q sqlx.QueryerContext, tableName string) ([]shadowIndex, error) {
+	var indexes []shadowIndex
+	err := sqlx.SelectContext(ctx, q, &indexes, `
+		SELECT
+			quote_ident(i.relname) AS name,
+			pg_get_indexdef(i.oid) AS definition,
+			COALESCE(pg_get_constraintdef(con.oid), '') AS constraint
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		LEFT JOIN pg_constraint con
+			ON con.conindid = ix.indexrelid AND con.conrelid = ix.indrelid AND con.contype IN ('p', 'u', 'x')
+		WHERE ix.indrelid = $1::regclass
+		ORDER BY i.oid
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("list indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// BuildShadowIndexes создает копии индексов и ограничений исходной таблицы под временными
+// именами, внешние ключи и статистику новой таблицы. Построение индексов на большой таблице
+// занимает больше таймаута пакета, поэтому statement_timeout в этой транзакции отключен.
+func (r *copySwapRepository) BuildShadowIndexes(ctx context.Context, cs entities.CopySwap) (err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.BuildShadowIndexes", cs.Table)
+	defer func() { endSpan(span, err) }()
+
+	defer func() { err = classifyError(err) }()
+
+	names := r.names(cs.Table)
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "SELECT set_config('statement_timeout', '0', true)"); err != nil {
+		return fmt.Errorf("disable statement_timeout: %w", err)
+	}
+
+	indexes, err := r.sourceIndexes(ctx, tx, cs.Table)
+	if err != nil {
+		return err
+	}
+
+	var statements []string
+	for _, idx := range indexes {
+		// Индекс ограничения создается вместе с ограничением и получает его имя
+		if idx.Constraint != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s",
+				cs.Shadow, names.index(idx.Name), idx.Constraint))
+			continue
+		}
+
+		stmt, err := rewriteIndexDef(idx.Definition, idx.Name, names.index(idx.Name), cs.Shadow)
+		if err != nil {
+			return err
+		}
+		statements = append(statements, stmt)
+	}
+
+	// Имена внешних ключей уникальны в пределах таблицы, поэтому переносятся без изменений
+	var foreignKeys []string
+	err = tx.SelectContext(ctx, &foreignKeys, `
+		SELECT format('ALTER TABLE %s ADD CONSTRAINT %I %s', $2::text, conname, pg_get_constraintdef(oid))
+		FROM pg_constraint
+		WHERE conrelid = $1::regclass AND contype = 'f'
+		ORDER BY oid
+	`, cs.Table, cs.Shadow)
+	if err != nil {
+		return fmt.Errorf("list foreign keys: %w", err)
+	}
+	statements = append(statements, foreignKeys...)
+	statements = append(statements, fmt.Sprintf("ANALYZE %s", cs.Shadow))
+
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("build indexes: %w", err)
+		}
+	}
+
+	if err = commit(tx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rewriteIndexDef переносит определение индекса исходной таблицы на новую таблицу под другим именем
+func rewriteIndexDef(def, name, newName, table string) (string, error) {
+	for _, prefix := range []string{"CREATE INDEX ", "CREATE UNIQUE INDEX "} {
+		header := prefix + name + " ON "
+		if !strings.HasPrefix(def, header) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(strings.TrimPrefix(def, header), "ONLY ")
+		if _, using, ok := strings.Cut(rest, " USING "); ok {
+			return fmt.Sprintf("%s%s ON %s USING %s", prefix, newName, table, using), nil
+		}
+	}
+	return "", fmt.Errorf("unexpected index definition: %s", def)
+}
+
+// ApplyDelta переносит в новую таблицу строки, измененные в исходной таблице после начала
+// копирования. Транзакция выполняется в REPEATABLE READ: записи журнала, зафиксированные
+// после ее начала, не удаляются и будут перенесены следующим вызовом.
+func (r *copySwapRepository) ApplyDelta(ctx context.Context, cs entities.CopySwap, batchSize int, timeouts entities.BatchTimeouts) (applied int, err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.ApplyDelta", cs.Table,
+		attribute.Int("cleanup.batch_size", batchSize))
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.delta_applied", applied))
+		endSpan(span, err)
+	}()
+
+	defer func() { err = classifyError(err) }()
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return 0, err
+	}
+
+	var upto sql.NullInt64
+	query := fmt.Sprintf("SELECT max(seq) FROM (SELECT seq FROM %s ORDER BY seq LIMIT $1) AS pending", cs.DeltaLog)
+	if err = tx.GetContext(ctx, &upto, query, batchSize); err != nil {
+		return 0, fmt.Errorf("read delta log: %w", err)
+	}
+	if upto.Valid {
+		if applied, err = r.applyDelta(ctx, tx, cs, upto.Int64); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = commit(tx); err != nil {
+		return 0, err
+	}
+
+	return applied, nil
+}
+
+// applyDelta заменяет в новой таблице строки, упомянутые в журнале до записи upto, их текущими
+// версиями из исходной таблицы и удаляет перенесенные записи журнала
+func (r *copySwapRepository) applyDelta(ctx context.Context, tx *sqlx.Tx, cs entities.CopySwap, upto int64) (int, error) {
+	columns, err := r.copyColumns(ctx, cs.Table)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := fmt.Sprintf("SELECT id FROM %s WHERE seq <= $1", cs.DeltaLog)
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", cs.Shadow, changed), upto); err != nil {
+		return 0, fmt.Errorf("remove changed rows: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE
+		SELECT %s FROM %s
+		WHERE id IN (%s) AND (created_at >= $2 OR created_at IS NULL)
+	`, cs.Shadow, columns, columns, cs.Table, changed), upto, cs.BeforeDate)
+	if err != nil {
+		return 0, fmt.Errorf("copy changed rows: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE seq <= $1", cs.DeltaLog), upto)
+	if err != nil {
+		return 0, fmt.Errorf("trim delta log: %w", err)
+	}
+	applied, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("read affected rows: %w", err)
+	}
+
+	return int(applied), nil
+}
+
+// SwapTables заменяет исходную таблицу новой. Под эксклюзивной блокировкой исходной таблицы
+// переносится остаток журнала, владелец, права, параметры хранения, комментарий
+// и последовательности; затем исходная таблица удаляется, а новая таблица, ее индексы,
+// ограничения и последовательности получают прежние имена. Ожидание блокировки ограничено
+// lock_timeout, поэтому замена не задерживает запросы к таблице дольше него.
+func (r *copySwapRepository) SwapTables(ctx context.Context, cs entities.CopySwap, timeouts entities.BatchTimeouts) (err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.SwapTables", cs.Table)
+	defer func() { endSpan(span, err) }()
+
+	defer func() { err = classifyError(err) }()
+
+	names := r.names(cs.Table)
+	_, table := r.splitTableName(cs.Table)
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", cs.Table)); err != nil {
+		return fmt.Errorf("lock table: %w", err)
+	}
+
+	// Пока держится блокировка, новых изменений нет: переносим весь журнал
+	var upto sql.NullInt64
+	if err = tx.GetContext(ctx, &upto, fmt.Sprintf("SELECT max(seq) FROM %s", cs.DeltaLog)); err != nil {
+		return fmt.Errorf("read delta log: %w", err)
+	}
+	if upto.Valid {
+		if _, err = r.applyDelta(ctx, tx, cs, upto.Int64); err != nil {
+			return err
+		}
+	}
+
+	indexes, err := r.sourceIndexes(ctx, tx, cs.Table)
+	if err != nil {
+		return err
+	}
+	var shadowIndexes []string
+	err = tx.SelectContext(ctx, &shadowIndexes,
+		"SELECT indexrelid::regclass::text FROM pg_index WHERE indrelid = $1::regclass", cs.Shadow)
+	if err != nil {
+		return fmt.Errorf("list indexes of the new table: %w", err)
+	}
+	for _, idx := range indexes {
+		if !containsIndex(shadowIndexes, names.schema, names.index(idx.Name)) {
+			return fmt.Errorf("index %s was created during the copy, run the cleanup again", idx.Name)
+		}
+	}
+
+	statements, err := r.swapStatements(ctx, tx, cs)
+	if err != nil {
+		return err
+	}
+
+	// Identity-колонки новой таблицы получили свои последовательности; продолжаем их
+	// с последнего значения исходных и после замены возвращаем им прежние имена
+	var identities []struct {
+		Setval string `db:"setval"`
+		Rename string `db:"rename"`
+	}
+	err = tx.SelectContext(ctx, &identities, `
+		SELECT
+			format('SELECT setval(%L, last_value, is_called) FROM %s',
+				pg_get_serial_sequence($2, a.attname), pg_get_serial_sequence($1, a.attname)) AS setval,
+			format('ALTER SEQUENCE %s RENAME TO %I', pg_get_serial_sequence($2, a.attname), s.relname) AS rename
+		FROM pg_attribute a
+		JOIN pg_class s ON s.oid = pg_get_serial_sequence($1, a.attname)::regclass
+		WHERE a.attrelid = $1::regclass AND a.attidentity <> '' AND NOT a.attisdropped
+	`, cs.Table, cs.Shadow)
+	if err != nil {
+		return fmt.Errorf("list identity columns: %w", err)
+	}
+	for _, id := range identities {
+		statements = append(statements, id.Setval)
+	}
+
+	// Замена: индексы, ограничения и последовательности исходной таблицы удаляются вместе с ней
+	statements = append(statements,
+		fmt.Sprintf("DROP TABLE %s", cs.Table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", cs.Shadow, table))
+	for _, idx := range indexes {
+		if idx.Constraint != "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s",
+				cs.Table, names.index(idx.Name), idx.Name))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER INDEX %s.%s RENAME TO %s",
+				names.schema, names.index(idx.Name), idx.Name))
+		}
+	}
+	for _, id := range identities {
+		statements = append(statements, id.Rename)
+	}
+	statements = append(statements,
+		fmt.Sprintf("DROP TABLE %s, %s", cs.DeltaLog, names.progress()),
+		fmt.Sprintf("DROP FUNCTION %s()", names.function()))
+
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("swap tables: %w", err)
+		}
+	}
+
+	if err = commit(tx); err != nil && isAmbiguousCommit(err) {
+		// Повторять замену, не узнав ее исход, нельзя: исходной таблицы уже может не быть
+		committed, checkErr := r.swapCommitted(ctx, cs, timeouts)
+		switch {
+		case checkErr != nil:
+			r.logger.Error("Failed to check the outcome of the table swap",
+				zap.String("table", cs.Table), zap.Error(checkErr))
+		case committed:
+			return nil
+		default:
+			return entities.TransientError{Err: err}
+		}
+	}
+
+	return err
+}
+
+// swapCommitted выясняет, зафиксирована ли замена, COMMIT которой остался без ответа.
+// Замена удаляет журнал изменений; блокировка журнала дожидается прерванной транзакции,
+// если сервер еще не завершил ее.
+func (r *copySwapRepository) swapCommitted(ctx context.Context, cs entities.CopySwap, timeouts entities.BatchTimeouts) (committed bool, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = setLocalTimeouts(ctx, tx, timeouts); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	if err = tx.GetContext(ctx, &exists, "SELECT to_regclass($1) IS NOT NULL", cs.DeltaLog); err != nil {
+		return false, fmt.Errorf("look up delta log: %w", err)
+	}
+	if !exists {
+		return true, nil
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS SHARE MODE", cs.DeltaLog)); err != nil {
+		// Журнал удален транзакцией, завершения которой ждала блокировка
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+			return true, nil
+		}
+		return false, fmt.Errorf("lock delta log: %w", err)
+	}
+
+	return false, nil
+}
+
+// swapStatements возвращает команды, переносящие на новую таблицу владельца, параметры
+// хранения, комментарий, права на таблицу и колонки и владение serial-последовательностями
+func (r *copySwapRepository) swapStatements(ctx context.Context, tx *sqlx.Tx, cs entities.CopySwap) ([]string, error) {
+	var table struct {
+		Owner   string `db:"owner"`
+		Options string `db:"options"`
+		Comment string `db:"comment"`
+		HasACL  bool   `db:"has_acl"`
+	}
+	err := tx.GetContext(ctx, &table, `
+		SELECT
+			quote_ident(pg_get_userbyid(relowner)) AS owner,
+			COALESCE(array_to_string(reloptions, ', '), '') AS options,
+			COALESCE(quote_literal(obj_description(oid, 'pg_class')), '') AS comment,
+			relacl IS NOT NULL AS has_acl
+		FROM pg_class
+		WHERE oid = $1::regclass
+	`, cs.Table)
+	if err != nil {
+		return nil, fmt.Errorf("read table properties: %w", err)
+	}
+
+	// Владелец меняется первым: права выдаются от его имени, а владение последовательностью
+	// требует одного владельца у таблицы и последовательности
+	statements := []string{fmt.Sprintf("ALTER TABLE %s OWNER TO %s", cs.Shadow, table.Owner)}
+	if table.Options != "" {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s SET (%s)", cs.Shadow, table.Options))
+	}
+	if table.Comment != "" {
+		statements = append(statements, fmt.Sprintf("COMMENT ON TABLE %s IS %s", cs.Shadow, table.Comment))
+	}
+
+	// Явные права заменяют права по умолчанию, в том числе права владельца
+	if table.HasACL {
+		statements = append(statements, fmt.Sprintf("REVOKE ALL ON %s FROM PUBLIC, %s", cs.Shadow, table.Owner))
+	}
+	var grants []string
+	err = tx.SelectContext(ctx, &grants, `
+		SELECT format('GRANT %s%s ON %s TO %s%s',
+			acl.privilege_type,
+			COALESCE(' (' || quote_ident(acl.column_name) || ')', ''),
+			$2::text,
+			CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(acl.grantee)) END,
+			CASE WHEN acl.is_grantable THEN ' WITH GRANT OPTION' ELSE '' END)
+		FROM (
+			SELECT NULL::name AS column_name, (aclexplode(relacl)).*
+			FROM pg_class WHERE oid = $1::regclass
+			UNION ALL
+			SELECT attname, (aclexplode(attacl)).*
+			FROM pg_attribute WHERE attrelid = $1::regclass AND attacl IS NOT NULL AND NOT attisdropped
+		) AS acl
+	`, cs.Table, cs.Shadow)
+	if err != nil {
+		return nil, fmt.Errorf("list grants: %w", err)
+	}
+	statements = append(statements, grants...)
+
+	// serial-последовательности используются обеими таблицами через значение по умолчанию;
+	// без смены владельца они удалились бы вместе с исходной таблицей
+	var sequences []string
+	err = tx.SelectContext(ctx, &sequences, `
+		SELECT format('ALTER SEQUENCE %s OWNED BY %s.%I', d.objid::regclass, $2::text, a.attname)
+		FROM pg_depend d
+		JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+		JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE d.classid = 'pg_class'::regclass AND d.refobjid = $1::regclass AND d.deptype = 'a'
+	`, cs.Table, cs.Shadow)
+	if err != nil {
+		return nil, fmt.Errorf("list owned sequences: %w", err)
+	}
+	statements = append(statements, sequences...)
+
+	return statements, nil
+}
+
+// containsIndex проверяет, есть ли индекс среди имен, возвращенных regclass::text
+func containsIndex(indexes []string, schema, name string) bool {
+	for _, idx := range indexes {
+		if idx == name || idx == schema+"."+name {
+			return true
+		}
+	}
+	return false
+}
+
+// AbortCopySwap удаляет триггеры исходной таблицы, новую таблицу, журнал и функцию триггера
+func (r *copySwapRepository) AbortCopySwap(ctx context.Context, cs entities.CopySwap) (err error) {
+	ctx, span := r.startSpan(ctx, "copySwapRepository.AbortCopySwap", cs.Table)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = r.dropCopySwapObjects(ctx, tx, cs.Table, r.names(cs.Table)); err != nil {
+		return err
+	}
+
+	if err = commit(tx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// dropCopySwapObjects удаляет вспомогательные объекты очистки заменой, если они есть.
+// Триггеры удаляются, только если существуют: DROP TRIGGER блокирует исходную таблицу.
+func (r *copySwapRepository) dropCopySwapObjects(ctx context.Context, tx *sqlx.Tx, tableName string, names copySwapNames) error {
+	var triggers []string
+	err := tx.SelectContext(ctx, &triggers,
+		"SELECT tgname FROM pg_trigger WHERE tgrelid = $1::regclass AND tgname IN ($2, $3)",
+		tableName, names.captureTrigger(), names.truncateTrigger())
+	if err != nil {
+		return fmt.Errorf("list copy and swap triggers: %w", err)
+	}
+
+	var statements []string
+	for _, trigger := range triggers {
+		statements = append(statements, fmt.Sprintf("DROP TRIGGER %s ON %s", trigger, tableName))
+	}
+	statements = append(statements,
+		fmt.Sprintf("DROP TABLE IF EXISTS %s, %s, %s", names.shadow(), names.delta(), names.progress()),
+		fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", names.function()))
+
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("drop copy and swap objects: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	_ "github.com/jackc/pgx/v4/stdlib" // Драйвер PostgreSQL
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Тесты очистки заменой работают с тестовой базой из CLEANER_TEST_POSTGRES_DSN
+// (см. cleaner_bench_test.go) и создают и удаляют таблицу copyswap_retry.
+
+func TestCopyBatchRetryAfterCommit(t *testing.T) {
+	dsn := os.Getenv("CLEANER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("CLEANER_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sqlx.Connect("pgx", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer db.Close()
+
+	const table = "public.copyswap_retry"
+	db.MustExec("DROP TABLE IF EXISTS " + table)
+	db.MustExec("CREATE TABLE " + table + " (id bigserial PRIMARY KEY, created_at timestamptz NOT NULL)")
+	defer db.Exec("DROP TABLE IF EXISTS " + table)
+
+	// 10 строк старше границы удаляются, 25 сохраняются
+	beforeDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	db.MustExec("INSERT INTO "+table+" (created_at) SELECT $1::timestamptz - g * interval '1 hour' FROM generate_series(1, 10) g", beforeDate)
+	db.MustExec("INSERT INTO "+table+" (created_at) SELECT $1::timestamptz + g * interval '1 hour' FROM generate_series(1, 25) g", beforeDate)
+
+	ctx := context.Background()
+	repo := NewCopySwapRepository(db, zap.NewNop()).(*copySwapRepository)
+	cs, err := repo.PrepareCopySwap(ctx, table, beforeDate, entities.BatchTimeouts{})
+	if err != nil {
+		t.Fatalf("PrepareCopySwap() error = %v", err)
+	}
+	defer repo.AbortCopySwap(ctx, cs)
+
+	// Повтор каждого пакета с прежним курсором имитирует COMMIT, который дошел до сервера,
+	// но ответ на него потерян: повтор продолжает копирование, а не копирует пакет снова
+	var cursor entities.BatchCursor
+	copied := 0
+	for i := 0; i < 10; i++ {
+		first, err := repo.CopyBatch(ctx, cs, 10, cursor, entities.BatchTimeouts{})
+		if err != nil {
+			t.Fatalf("CopyBatch() error = %v", err)
+		}
+		retry, err := repo.CopyBatch(ctx, cs, 10, cursor, entities.BatchTimeouts{})
+		if err != nil {
+			t.Fatalf("CopyBatch() retry error = %v", err)
+		}
+		copied += first.Deleted + retry.Deleted
+		if retry.Exhausted || first.Exhausted {
+			break
+		}
+		cursor = retry.Next
+	}
+
+	var shadowRows, distinctIDs int
+	if err := db.QueryRow("SELECT count(*), count(DISTINCT id) FROM "+cs.Shadow).Scan(&shadowRows, &distinctIDs); err != nil {
+		t.Fatal(err)
+	}
+	if copied != 25 || shadowRows != 25 || distinctIDs != 25 {
+		t.Errorf("copied %d rows, new table has %d rows and %d ids, want 25 each", copied, shadowRows, distinctIDs)
+	}
+
+	if err := repo.BuildShadowIndexes(ctx, cs); err != nil {
+		t.Fatalf("BuildShadowIndexes() error = %v", err)
+	}
+
+	// До замены журнал на месте, после нее замена считается зафиксированной
+	if committed, err := repo.swapCommitted(ctx, cs, entities.BatchTimeouts{}); err != nil || committed {
+		t.Fatalf("swapCommitted() before swap = %v, %v, want false", committed, err)
+	}
+	if err := repo.SwapTables(ctx, cs, entities.BatchTimeouts{}); err != nil {
+		t.Fatalf("SwapTables() error = %v", err)
+	}
+	if committed, err := repo.swapCommitted(ctx, cs, entities.BatchTimeouts{}); err != nil || !committed {
+		t.Fatalf("swapCommitted() after swap = %v, %v, want true", committed, err)
+	}
+
+	var rows int
+	if err := db.Get(&rows, "SELECT count(*) FROM "+table); err != nil || rows != 25 {
+		t.Errorf("table has %d rows (%v), want 25", rows, err)
+	}
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Коды SQLSTATE, после которых пакет можно безопасно повторить
+var transientSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"55P03": true, // lock_not_available
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// commitError - ошибка COMMIT. Если сервер не ответил, неизвестно, зафиксирована ли транзакция
+type commitError struct {
+	err error
+}
+
+func (e commitError) Error() string { return "commit transaction: " + e.err.Error() }
+func (e commitError) Unwrap() error { return e.err }
+
+// commit фиксирует транзакцию шага, который нельзя повторять вслепую: обрыв соединения
+// во время COMMIT не считается временной ошибкой
+func commit(tx *sqlx.Tx) error {
+	if err := tx.Commit(); err != nil {
+		return commitError{err: err}
+	}
+	return nil
+}
+
+// isAmbiguousCommit сообщает, что COMMIT не получил ответа сервера после отправки
+func isAmbiguousCommit(err error) bool {
+	var commitErr commitError
+	var pgErr *pgconn.PgError
+	return errors.As(err, &commitErr) && !errors.As(err, &pgErr) && !pgconn.SafeToRetry(err)
+}
+
+// classifyError помечает временные ошибки PostgreSQL как entities.TransientError.
+// Остальные ошибки, включая отмену контекста и COMMIT без ответа сервера, возвращаются
+// без изменений и считаются фатальными.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "55P03":
+			// lock_timeout: блокировка может освободиться, поэтому пакет повторяется
+			return entities.TransientError{Code: pgErr.Code, Err: entities.TimeoutError{Kind: entities.TimeoutLock, Err: err}}
+		case pgErr.Code == "57014" && strings.Contains(pgErr.Message, "statement timeout"):
+			// query_canceled также возвращается при отмене запроса клиентом, поэтому смотрим на причину
+			return entities.TimeoutError{Kind: entities.TimeoutStatement, Err: err}
+		case pgErr.Code == "25P03":
+			return entities.TimeoutError{Kind: entities.TimeoutIdleInTransaction, Err: err}
+		}
+		// Класс 08 - ошибки соединения
+		if transientSQLStates[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08") {
+			return entities.TransientError{Code: pgErr.Code, Err: err}
+		}
+		return err
+	}
+
+	// Транзакция могла быть зафиксирована, повтор шага небезопасен
+	if isAmbiguousCommit(err) {
+		return err
+	}
+
+	// Соединение разорвано до или во время выполнения запроса
+	var netErr net.Error
+	if pgconn.SafeToRetry(err) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.As(err, &netErr) {
+		return entities.TransientError{Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestClassifyError(t *testing.T) {
+	pgError := func(code string) error {
+		return &pgconn.PgError{Code: code, Message: "test"}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantTransient bool
+		wantCode      string
+	}{
+		{"serialization failure", pgError("40001"), true, "40001"},
+		{"deadlock", pgError("40P01"), true, "40P01"},
+		{"lock not available", pgError("55P03"), true, "55P03"},
+		{"too many connections", pgError("53300"), true, "53300"},
+		{"admin shutdown", pgError("57P01"), true, "57P01"},
+		{"cannot connect now", pgError("57P03"), true, "57P03"},
+		{"connection failure class", pgError("08006"), true, "08006"},
+		{"wrapped deadlock", fmt.Errorf("execute delete query: %w", pgError("40P01")), true, "40P01"},
+		{"unique violation", pgError("23505"), false, ""},
+		{"undefined table", pgError("42P01"), false, ""},
+		{"broken connection", driver.ErrBadConn, true, ""},
+		{"unexpected EOF", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true, ""},
+		{"network error", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true, ""},
+		{"context canceled", context.Canceled, false, ""},
+		{"context deadline", context.DeadlineExceeded, false, ""},
+		{"other error", errors.New("syntax error"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classifyError() = %v, want it to wrap %v", got, tt.err)
+			}
+			var transient entities.TransientError
+			if isTransient := errors.As(got, &transient); isTransient != tt.wantTransient {
+				t.Fatalf("transient = %v, want %v", isTransient, tt.wantTransient)
+			}
+			if tt.wantTransient && transient.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", transient.Code, tt.wantCode)
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+}
+
+func TestClassifyTimeoutError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *pgconn.PgError
+		wantKind      string // Пусто - ошибка не считается таймаутом
+		wantTransient bool
+	}{
+		{"lock timeout", &pgconn.PgError{Code: "55P03", Message: "canceling statement due to lock timeout"}, entities.TimeoutLock, true},
+		{"statement timeout", &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, entities.TimeoutStatement, false},
+		{"idle in transaction timeout", &pgconn.PgError{Code: "25P03", Message: "terminating connection due to idle-in-transaction timeout"}, entities.TimeoutIdleInTransaction, false},
+		{"query canceled by user", &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+
+			var timeoutErr entities.TimeoutError
+			isTimeout := errors.As(got, &timeoutErr)
+			if isTimeout != (tt.wantKind != "") || timeoutErr.Kind != tt.wantKind {
+				t.Errorf("timeout kind = %q (timeout %v), want %q", timeoutErr.Kind, isTimeout, tt.wantKind)
+			}
+			if transient := entities.IsTransient(got); transient != tt.wantTransient {
+				t.Errorf("transient = %v, want %v", transient, tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestFormatMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{5 * time.Second, "5000ms"},
+		{1500 * time.Millisecond, "1500ms"},
+		// Ноль отключил бы таймаут, поэтому доли миллисекунды округляются вверх
+		{time.Microsecond, "1ms"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMillis(tt.d); got != tt.want {
+			t.Errorf("formatMillis(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCommitError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantTransient bool
+	}{
+		// Неизвестно, зафиксирована ли транзакция: повтор мог бы выполнить шаг дважды
+		{"connection lost during commit", commitError{err: io.ErrUnexpectedEOF}, false},
+		{"network error during commit", commitError{err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}, false},
+		// Сервер ответил ошибкой: транзакция откатилась
+		{"serialization failure on commit", commitError{err: &pgconn.PgError{Code: "40001"}}, true},
+		{"connection lost outside commit", fmt.Errorf("execute copy query: %w", io.ErrUnexpectedEOF), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(fmt.Errorf("swap tables: %w", tt.err))
+			if transient := entities.IsTransient(got); transient != tt.wantTransient {
+				t.Errorf("transient = %v, want %v", transient, tt.wantTransient)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type healthRepository struct {
+	db *sqlx.DB
+}
+
+// NewHealthRepository создает репозиторий для проверки состояния PostgreSQL
+func NewHealthRepository(db *sqlx.DB) ports.HealthRepository {
+	return &healthRepository{db: db}
+}
+
+// Ping проверяет доступность базы данных
+func (r *healthRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// PoolStats возвращает статистику пула соединений
+func (r *healthRepository) PoolStats() entities.PoolStats {
+	stats := r.db.Stats()
+
+	ps := entities.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+	}
+	if stats.MaxOpenConnections > 0 {
+		ps.Saturation = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+
+	return ps
+}
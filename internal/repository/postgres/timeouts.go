@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// setLocalTimeouts устанавливает таймауты для текущей транзакции (аналог SET LOCAL).
+// set_config используется потому, что SET не принимает параметры запроса.
+func setLocalTimeouts(ctx context.Context, tx *sqlx.Tx, timeouts entities.BatchTimeouts) error {
+	settings := []struct {
+		name  string
+		value entities.Duration
+	}{
+		{"statement_timeout", timeouts.Statement},
+		{"lock_timeout", timeouts.Lock},
+		{"idle_in_transaction_session_timeout", timeouts.IdleInTransaction},
+	}
+
+	for _, s := range settings {
+		if s.value <= 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", s.name, formatMillis(time.Duration(s.value))); err != nil {
+			return fmt.Errorf("set %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+// formatMillis переводит длительность в миллисекунды; значение 0 отключило бы таймаут,
+// поэтому длительности меньше миллисекунды округляются вверх
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%dms", max(d.Milliseconds(), 1))
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type deadLetterRepository struct {
+	db *sqlx.DB
+}
+
+// NewDeadLetterRepository создает хранилище недоставленных уведомлений в PostgreSQL
+func NewDeadLetterRepository(db *sqlx.DB) ports.DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+// Save сохраняет недоставленное уведомление
+func (r *deadLetterRepository) Save(ctx context.Context, letter *entities.WebhookDeadLetter) (err error) {
+	ctx, span := tracer.Start(ctx, "deadLetterRepository.Save")
+	defer func() { endSpan(span, err) }()
+
+	err = r.db.GetContext(ctx, letter, `
+		INSERT INTO webhook_dead_letters (event_id, task_id, url, payload, attempts, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, event_id, task_id, url, payload, attempts, last_error, created_at
+	`, letter.EventID, letter.TaskID, letter.URL, string(letter.Payload), letter.Attempts, letter.LastError)
+	if err != nil {
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+
+	return nil
+}
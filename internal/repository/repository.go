@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/config"
+	"data-cleaner/internal/pkg/mysql"
+	"data-cleaner/internal/pkg/postgres"
+	"data-cleaner/internal/pkg/sqlite"
+	mysqlrepo "data-cleaner/internal/repository/mysql"
+	pgrepo "data-cleaner/internal/repository/postgres"
+	sqliterepo "data-cleaner/internal/repository/sqlite"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Repositories объединяет репозитории основной базы и дополнительных источников данных
+type Repositories struct {
+	DB          *sqlx.DB
+	Cleaner     ports.CleanerRepository
+	Audit       ports.AuditRepository
+	DeadLetters ports.DeadLetterRepository
+	Health      ports.HealthRepository
+
+	// CopySwap очищает таблицу заменой; nil, если ее не поддерживает ни один драйвер
+	CopySwap ports.CopySwapRepository
+
+	close func()
+}
+
+// Open подключается к основной базе данных и дополнительным источникам из конфигурации.
+// Журнал аудита и недоставленные уведомления хранятся в основной базе, а операции
+// очистки направляются в источник, которому принадлежит таблица.
+func Open(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Repositories, error) {
+	repos, err := open(ctx, cfg, logger)
+	if err != nil || len(cfg.Datasources) == 0 {
+		return repos, err
+	}
+
+	primary := datasource{name: "database", cleaner: repos.Cleaner, copySwap: repos.CopySwap, health: repos.Health}
+	closers := []func(){repos.close}
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var sources []datasource
+	copySwap := repos.CopySwap != nil
+	for _, ds := range cfg.Datasources {
+		dsRepos, err := open(ctx, cfg.ForDatasource(ds), logger.With(zap.String("datasource", ds.Name)))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("open datasource %s: %w", ds.Name, err)
+		}
+		closers = append(closers, dsRepos.close)
+		sources = append(sources, datasource{
+			name:     ds.Name,
+			tables:   ds.Tables,
+			cleaner:  dsRepos.Cleaner,
+			copySwap: dsRepos.CopySwap,
+			health:   dsRepos.Health,
+		})
+		copySwap = copySwap || dsRepos.CopySwap != nil
+	}
+
+	r := newRouter(primary, sources)
+	repos.Cleaner = newCleanerRouter(r)
+	repos.Health = newHealthRouter(r)
+	repos.CopySwap = nil
+	if copySwap {
+		repos.CopySwap = newCopySwapRouter(r)
+	}
+	repos.close = closeAll
+
+	return repos, nil
+}
+
+// open подключается к базе данных и создает репозитории для ее драйвера
+func open(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Repositories, error) {
+	switch cfg.DBDriver {
+	case config.DriverPostgres:
+		db, err := postgres.NewPostgresDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &Repositories{
+			DB:          db,
+			Cleaner:     pgrepo.NewPostgresRepository(db, logger.Named("repository")),
+			Audit:       pgrepo.NewAuditRepository(db, logger.Named("audit")),
+			DeadLetters: pgrepo.NewDeadLetterRepository(db),
+			Health:      pgrepo.NewHealthRepository(db),
+			CopySwap:    pgrepo.NewCopySwapRepository(db, logger.Named("copyswap")),
+			close:       func() { postgres.CloseDB(db, logger) },
+		}, nil
+	case config.DriverMySQL:
+		db, err := mysql.NewMySQLDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &Repositories{
+			DB:          db,
+			Cleaner:     mysqlrepo.NewMySQLRepository(db, logger.Named("repository")),
+			Audit:       mysqlrepo.NewAuditRepository(db, logger.Named("audit")),
+			DeadLetters: mysqlrepo.NewDeadLetterRepository(db),
+			Health:      mysqlrepo.NewHealthRepository(db),
+			close:       func() { mysql.CloseDB(db, logger) },
+		}, nil
+	case config.DriverSQLite:
+		db, err := sqlite.NewSQLiteDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &Repositories{
+			DB:          db,
+			Cleaner:     sqliterepo.NewSQLiteRepository(db, logger.Named("repository")),
+			Audit:       sqliterepo.NewAuditRepository(db, logger.Named("audit")),
+			DeadLetters: sqliterepo.NewDeadLetterRepository(db),
+			Health:      sqliterepo.NewHealthRepository(db),
+			close:       func() { sqlite.CloseDB(db, logger) },
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.DBDriver)
+	}
+}
+
+// Close закрывает подключение к базе данных
+func (r *Repositories) Close() {
+	r.close()
+}
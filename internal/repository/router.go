@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+)
+
+// datasource связывает шаблоны таблиц с репозиториями источника данных
+type datasource struct {
+	name     string
+	tables   []string
+	cleaner  ports.CleanerRepository
+	copySwap ports.CopySwapRepository // nil, если драйвер не поддерживает очистку заменой
+	health   ports.HealthRepository
+}
+
+// router направляет операции с таблицей в репозиторий источника данных, шаблонам
+// которого она соответствует; остальные таблицы обслуживает основная база
+type router struct {
+	sources []datasource // первым идет основная база
+}
+
+// newRouter создает маршрутизатор таблиц по источникам данных
+func newRouter(primary datasource, datasources []datasource) *router {
+	return &router{sources: append([]datasource{primary}, datasources...)}
+}
+
+// route возвращает источник данных таблицы
+func (r *router) route(tableName string) *datasource {
+	for i := 1; i < len(r.sources); i++ {
+		for _, pattern := range r.sources[i].tables {
+			if entities.MatchTable(pattern, tableName) {
+				return &r.sources[i]
+			}
+		}
+	}
+	return &r.sources[0]
+}
+
+// cleanerRouter реализует ports.CleanerRepository поверх нескольких источников данных
+type cleanerRouter struct {
+	*router
+}
+
+// newCleanerRouter создает репозиторий, который выбирает источник данных по имени таблицы
+func newCleanerRouter(r *router) ports.CleanerRepository {
+	return &cleanerRouter{router: r}
+}
+
+func (r *cleanerRouter) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (entities.BatchResult, error) {
+	return r.route(tableName).cleaner.DeleteBatch(ctx, tableName, beforeDate, batchSize, cursor, timeouts)
+}
+
+func (r *cleanerRouter) TruncateIfExpired(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (entities.TruncateResult, error) {
+	return r.route(tableName).cleaner.TruncateIfExpired(ctx, tableName, beforeDate, timeouts)
+}
+
+func (r *cleanerRouter) TryAcquireLock(ctx context.Context, tableName string) (bool, func(), error) {
+	return r.route(tableName).cleaner.TryAcquireLock(ctx, tableName)
+}
+
+func (r *cleanerRouter) IsLocked(ctx context.Context, tableName string) (bool, error) {
+	return r.route(tableName).cleaner.IsLocked(ctx, tableName)
+}
+
+func (r *cleanerRouter) CountRows(ctx context.Context, tableName string, beforeDate time.Time) (int, error) {
+	return r.route(tableName).cleaner.CountRows(ctx, tableName, beforeDate)
+}
+
+func (r *cleanerRouter) EstimateRows(ctx context.Context, tableName string, beforeDate time.Time, limit int) (int, error) {
+	return r.route(tableName).cleaner.EstimateRows(ctx, tableName, beforeDate, limit)
+}
+
+func (r *cleanerRouter) ValidateTable(ctx context.Context, tableName string) error {
+	return r.route(tableName).cleaner.ValidateTable(ctx, tableName)
+}
+
+func (r *cleanerRouter) ListPartitions(ctx context.Context, tableName string, beforeDate time.Time) ([]string, error) {
+	return r.route(tableName).cleaner.ListPartitions(ctx, tableName, beforeDate)
+}
+
+// ListTables объединяет таблицы всех источников. Таблица попадает в список только
+// из того источника, в который направляются операции с ней.
+func (r *cleanerRouter) ListTables(ctx context.Context) ([]entities.TableInfo, error) {
+	var tables []entities.TableInfo
+	for i := range r.sources {
+		ds := &r.sources[i]
+		infos, err := ds.cleaner.ListTables(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list tables of datasource %s: %w", ds.name, err)
+		}
+		for _, info := range infos {
+			if r.route(info.Name) == ds {
+				tables = append(tables, info)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// copySwapRouter реализует ports.CopySwapRepository поверх нескольких источников данных
+type copySwapRouter struct {
+	*router
+}
+
+// newCopySwapRouter создает репозиторий очистки заменой, который выбирает источник
+// данных по имени таблицы
+func newCopySwapRouter(r *router) ports.CopySwapRepository {
+	return &copySwapRouter{router: r}
+}
+
+// repo возвращает репозиторий очистки заменой источника таблицы
+func (r *copySwapRouter) repo(tableName string) (ports.CopySwapRepository, error) {
+	repo := r.route(tableName).copySwap
+	if repo == nil {
+		return nil, entities.ErrCopySwapNotSupported
+	}
+	return repo, nil
+}
+
+func (r *copySwapRouter) PrepareCopySwap(ctx context.Context, tableName string, beforeDate time.Time, timeouts entities.BatchTimeouts) (entities.CopySwap, error) {
+	repo, err := r.repo(tableName)
+	if err != nil {
+		return entities.CopySwap{}, err
+	}
+	return repo.PrepareCopySwap(ctx, tableName, beforeDate, timeouts)
+}
+
+func (r *copySwapRouter) CopyBatch(ctx context.Context, cs entities.CopySwap, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (entities.BatchResult, error) {
+	repo, err := r.repo(cs.Table)
+	if err != nil {
+		return entities.BatchResult{}, err
+	}
+	return repo.CopyBatch(ctx, cs, batchSize, cursor, timeouts)
+}
+
+func (r *copySwapRouter) BuildShadowIndexes(ctx context.Context, cs entities.CopySwap) error {
+	repo, err := r.repo(cs.Table)
+	if err != nil {
+		return err
+	}
+	return repo.BuildShadowIndexes(ctx, cs)
+}
+
+func (r *copySwapRouter) ApplyDelta(ctx context.Context, cs entities.CopySwap, batchSize int, timeouts entities.BatchTimeouts) (int, error) {
+	repo, err := r.repo(cs.Table)
+	if err != nil {
+		return 0, err
+	}
+	return repo.ApplyDelta(ctx, cs, batchSize, timeouts)
+}
+
+func (r *copySwapRouter) SwapTables(ctx context.Context, cs entities.CopySwap, timeouts entities.BatchTimeouts) error {
+	repo, err := r.repo(cs.Table)
+	if err != nil {
+		return err
+	}
+	return repo.SwapTables(ctx, cs, timeouts)
+}
+
+func (r *copySwapRouter) AbortCopySwap(ctx context.Context, cs entities.CopySwap) error {
+	repo, err := r.repo(cs.Table)
+	if err != nil {
+		return err
+	}
+	return repo.AbortCopySwap(ctx, cs)
+}
+
+// healthRouter проверяет доступность всех источников данных
+type healthRouter struct {
+	*router
+}
+
+// newHealthRouter создает проверку состояния всех источников данных
+func newHealthRouter(r *router) ports.HealthRepository {
+	return &healthRouter{router: r}
+}
+
+func (r *healthRouter) Ping(ctx context.Context) error {
+	for _, ds := range r.sources {
+		if err := ds.health.Ping(ctx); err != nil {
+			return fmt.Errorf("datasource %s: %w", ds.name, err)
+		}
+	}
+	return nil
+}
+
+// PoolStats возвращает статистику пула основной базы
+func (r *healthRouter) PoolStats() entities.PoolStats {
+	return r.sources[0].health.PoolStats()
+}
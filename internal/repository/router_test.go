@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+)
+
+// namedCleaner сообщает, какой источник данных обработал операцию
+type namedCleaner struct {
+	ports.CleanerRepository
+	name   string
+	tables []string
+	calls  *[]string
+}
+
+func (c namedCleaner) ValidateTable(_ context.Context, tableName string) error {
+	*c.calls = append(*c.calls, c.name+":"+tableName)
+	return nil
+}
+
+func (c namedCleaner) ListTables(context.Context) ([]entities.TableInfo, error) {
+	var infos []entities.TableInfo
+	for _, name := range c.tables {
+		infos = append(infos, entities.TableInfo{Name: name})
+	}
+	return infos, nil
+}
+
+// namedCopySwap сообщает, какой источник данных начал очистку заменой
+type namedCopySwap struct {
+	ports.CopySwapRepository
+	name string
+}
+
+func (c namedCopySwap) PrepareCopySwap(_ context.Context, tableName string, beforeDate time.Time, _ entities.BatchTimeouts) (entities.CopySwap, error) {
+	return entities.CopySwap{Table: tableName, Shadow: c.name}, nil
+}
+
+func TestRouterSelectsDatasourceByTable(t *testing.T) {
+	var calls []string
+	r := newRouter(
+		datasource{
+			name:     "database",
+			cleaner:  namedCleaner{name: "database", tables: []string{"public.events", "legacy.orders"}, calls: &calls},
+			copySwap: namedCopySwap{name: "database"},
+		},
+		[]datasource{
+			{name: "archive", tables: []string{"archive_*"}, cleaner: namedCleaner{name: "archive", tables: []string{"public.archive_2023"}, calls: &calls}},
+			{name: "legacy", tables: []string{"legacy.*"}, cleaner: namedCleaner{name: "legacy", tables: []string{"legacy.orders"}, calls: &calls}},
+		},
+	)
+	cleaner := newCleanerRouter(r)
+	ctx := context.Background()
+
+	for _, table := range []string{"events", "archive_2023", "legacy.orders", "public.archive_2024"} {
+		if err := cleaner.ValidateTable(ctx, table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"database:events", "archive:archive_2023", "legacy:legacy.orders", "archive:public.archive_2024"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	// Таблица основной базы, подходящая под шаблон источника, в список не попадает
+	tables, err := cleaner.ListTables(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range tables {
+		names = append(names, info.Name)
+	}
+	if want := []string{"public.events", "public.archive_2023", "legacy.orders"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListTables() = %v, want %v", names, want)
+	}
+
+	copySwap := newCopySwapRouter(r)
+	if cs, err := copySwap.PrepareCopySwap(ctx, "events", time.Now(), entities.BatchTimeouts{}); err != nil || cs.Shadow != "database" {
+		t.Errorf("PrepareCopySwap(events) = %+v, %v, want the primary database", cs, err)
+	}
+	if _, err := copySwap.PrepareCopySwap(ctx, "legacy.orders", time.Now(), entities.BatchTimeouts{}); !errors.Is(err, entities.ErrCopySwapNotSupported) {
+		t.Errorf("PrepareCopySwap(legacy.orders) error = %v, want %v", err, entities.ErrCopySwapNotSupported)
+	}
+}
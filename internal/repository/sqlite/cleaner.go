@@ -0,0 +1,433 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("data-cleaner/repository/sqlite")
+
+// Формат дат SQLite (как у datetime() и CURRENT_TIMESTAMP). Колонка created_at
+// сравнивается как текст, поэтому даты в таблицах должны храниться в UTC в этом формате
+// или в формате драйвера с суффиксом часового пояса.
+const timeFormat = "2006-01-02 15:04:05.999999999"
+
+// Время жизни записи блокировки; пока очистка идет, запись продлевается
+const lockTTL = 30 * time.Second
+
+type sqliteRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр SQLite репозитория
+func NewSQLiteRepository(db *sqlx.DB, logger *zap.Logger) ports.CleanerRepository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// DeleteBatch удаляет самые старые записи пакетом по их rowid.
+// В SQLite нет серверных таймаутов: ожидание блокировки ограничено _busy_timeout
+// строки подключения, а длительность пакета - клиентским контекстом.
+// Курсор не используется: удаленные строки сразу исчезают из индекса, а запись
+// блокирует всю базу, поэтому пакет меньше batchSize означает конец диапазона.
+func (r *sqliteRepository) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, _ entities.BatchCursor, _ entities.BatchTimeouts) (result entities.BatchResult, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.DeleteBatch", tableName,
+		attribute.Int("cleanup.batch_size", batchSize))
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_deleted", result.Deleted))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return result, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Временные ошибки помечаются, чтобы сервис мог повторить пакет
+	defer func() { err = classifyError(err) }()
+
+	table := r.quoteTableName(tableName)
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE rowid IN (
+			SELECT rowid FROM %s
+			WHERE created_at < ?
+			ORDER BY created_at
+			LIMIT ?
+		)
+	`, table, table)
+
+	res, err := r.db.ExecContext(ctx, query, formatTime(beforeDate), batchSize)
+	if err != nil {
+		return result, fmt.Errorf("execute delete query: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return result, fmt.Errorf("read affected rows: %w", err)
+	}
+
+	result.Deleted = int(affected)
+	result.Exhausted = result.Deleted < batchSize
+	return result, nil
+}
+
+// TruncateIfExpired удаляет все записи таблицы одним запросом, если все они старше beforeDate.
+// В SQLite нет TRUNCATE; условие проверяется в том же запросе, который удаляет строки,
+// поэтому между проверкой и удалением другие писатели не могут изменить таблицу.
+func (r *sqliteRepository) TruncateIfExpired(ctx context.Context, tableName string, beforeDate time.Time, _ entities.BatchTimeouts) (result entities.TruncateResult, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.TruncateIfExpired", tableName)
+	defer func() {
+		span.SetAttributes(
+			attribute.Bool("cleanup.truncated", result.Truncated),
+			attribute.Int("cleanup.rows_deleted", result.Rows))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return result, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	defer func() { err = classifyError(err) }()
+
+	table := r.quoteTableName(tableName)
+	res, err := r.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE (SELECT max(created_at) FROM %s) < ?
+	`, table, table), formatTime(beforeDate))
+	if err != nil {
+		return result, fmt.Errorf("execute delete query: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return result, fmt.Errorf("read affected rows: %w", err)
+	}
+	if affected > 0 {
+		return entities.TruncateResult{Truncated: true, Rows: int(affected)}, nil
+	}
+
+	// Ничего не удалено: таблица пуста или в ней есть новые записи
+	var latest *string
+	if err = r.db.GetContext(ctx, &latest, fmt.Sprintf("SELECT max(created_at) FROM %s", table)); err != nil {
+		return result, fmt.Errorf("find latest row: %w", err)
+	}
+	if latest == nil {
+		result.Reason = "table is empty"
+	} else {
+		result.Reason = fmt.Sprintf("table has rows newer than before_date (latest %s)", *latest)
+	}
+
+	return result, nil
+}
+
+// CountRows возвращает количество записей старше указанной даты
+func (r *sqliteRepository) CountRows(ctx context.Context, tableName string, beforeDate time.Time) (count int, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.CountRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_matched", count))
+		endSpan(span, err)
+	}()
+
+	// Санитизация имени таблицы
+	if !r.isValidTableName(tableName) {
+		return 0, entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE created_at < ?`, r.quoteTableName(tableName))
+	if err = r.db.GetContext(ctx, &count, query, formatTime(beforeDate)); err != nil {
+		return 0, fmt.Errorf("count rows: %w", err)
+	}
+
+	return count, nil
+}
+
+// EstimateRows оценивает количество записей старше указанной даты.
+// Планировщик SQLite не дает оценок по диапазону, поэтому сверх limit считаем точно:
+// для локальных баз это дешево.
+func (r *sqliteRepository) EstimateRows(ctx context.Context, tableName string, beforeDate time.Time, limit int) (estimate int, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.EstimateRows", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Int("cleanup.rows_estimated", estimate))
+		endSpan(span, err)
+	}()
+
+	return r.CountRows(ctx, tableName, beforeDate)
+}
+
+// TryAcquireLock пытается занять запись таблицы в cleanup_locks.
+// Запись продлевается в фоне, а запись упавшего процесса освобождается по истечении lockTTL.
+func (r *sqliteRepository) TryAcquireLock(ctx context.Context, tableName string) (acquired bool, unlock func(), err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.TryAcquireLock", tableName)
+	defer func() {
+		span.SetAttributes(attribute.Bool("lock.acquired", acquired))
+		endSpan(span, err)
+	}()
+
+	owner := uuid.New().String()
+	now := time.Now()
+
+	// Вставляем запись или забираем просроченную
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO cleanup_locks (table_name, owner, acquired_at, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (table_name) DO UPDATE SET
+			owner = excluded.owner,
+			acquired_at = excluded.acquired_at,
+			expires_at = excluded.expires_at
+		WHERE cleanup_locks.expires_at < excluded.acquired_at
+	`, tableName, owner, now.UnixMilli(), now.Add(lockTTL).UnixMilli())
+	if err != nil {
+		return false, nil, fmt.Errorf("acquire table lock: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, nil, fmt.Errorf("acquire table lock: %w", err)
+	}
+	if affected == 0 {
+		return false, nil, nil
+	}
+
+	// Продлеваем блокировку, пока она не освобождена
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.keepLock(tableName, owner, stop)
+	}()
+
+	// Возвращаем функцию для освобождения блокировки
+	unlock = func() {
+		close(stop)
+		<-done
+
+		_, err := r.db.ExecContext(context.Background(),
+			"DELETE FROM cleanup_locks WHERE table_name = ? AND owner = ?", tableName, owner)
+		if err != nil {
+			r.logger.Error("Failed to release table lock",
+				zap.String("table", tableName),
+				zap.Error(err))
+		}
+	}
+
+	return true, unlock, nil
+}
+
+// IsLocked проверяет, есть ли у таблицы непросроченная запись в cleanup_locks
+func (r *sqliteRepository) IsLocked(ctx context.Context, tableName string) (locked bool, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.IsLocked", tableName)
+	defer func() { endSpan(span, err) }()
+
+	err = r.db.GetContext(ctx, &locked,
+		"SELECT EXISTS (SELECT 1 FROM cleanup_locks WHERE table_name = ? AND expires_at >= ?)",
+		tableName, time.Now().UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("check table lock: %w", err)
+	}
+
+	return locked, nil
+}
+
+// keepLock продлевает запись блокировки до закрытия stop
+func (r *sqliteRepository) keepLock(tableName, owner string, stop <-chan struct{}) {
+	ticker := time.NewTicker(lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			res, err := r.db.Exec(
+				"UPDATE cleanup_locks SET expires_at = ? WHERE table_name = ? AND owner = ?",
+				time.Now().Add(lockTTL).UnixMilli(), tableName, owner)
+			if err != nil {
+				r.logger.Warn("Failed to extend table lock",
+					zap.String("table", tableName),
+					zap.Error(err))
+				continue
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				r.logger.Error("Table lock was taken over by another process",
+					zap.String("table", tableName))
+				return
+			}
+		}
+	}
+}
+
+// ValidateTable проверяет существование таблицы и наличие индекса по дате
+func (r *sqliteRepository) ValidateTable(ctx context.Context, tableName string) (err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.ValidateTable", tableName)
+	defer func() { endSpan(span, err) }()
+
+	if !r.isValidTableName(tableName) {
+		return entities.NewDomainError(fmt.Sprintf("invalid table name: %s", tableName))
+	}
+
+	// Проверяем существование таблицы; пакетное удаление требует rowid
+	schema, table := r.splitTableName(tableName)
+	var withoutRowID []bool
+	err = r.db.SelectContext(ctx, &withoutRowID, `
+		SELECT wr FROM pragma_table_list(?) WHERE schema = ? AND type = 'table'
+	`, table, schema)
+	if err != nil {
+		return fmt.Errorf("check table existence: %w", err)
+	}
+
+	if len(withoutRowID) == 0 {
+		return entities.NotFoundError{Resource: entities.ResourceTable, Name: tableName}
+	}
+	if withoutRowID[0] {
+		return fmt.Errorf("table %s is a WITHOUT ROWID table and cannot be cleaned in batches", tableName)
+	}
+
+	// Проверяем, что created_at является первой колонкой какого-либо индекса
+	var exists bool
+	err = r.db.GetContext(ctx, &exists, `
+		SELECT EXISTS (
+			SELECT 1 FROM pragma_index_list(?, ?) il
+			JOIN pragma_index_info(il.name, ?) ii
+			WHERE ii.seqno = 0 AND ii.name = 'created_at'
+		)
+	`, table, schema, schema)
+	if err != nil {
+		return fmt.Errorf("check index existence: %w", err)
+	}
+
+	if !exists {
+		r.logger.Warn("Table doesn't have index on created_at column, operation may be slow",
+			zap.String("table", tableName))
+	}
+
+	return nil
+}
+
+// ListPartitions возвращает пустой список: в SQLite нет секционирования
+func (r *sqliteRepository) ListPartitions(context.Context, string, time.Time) ([]string, error) {
+	return nil, nil
+}
+
+// ListTables возвращает таблицы основной базы с колонкой created_at
+func (r *sqliteRepository) ListTables(ctx context.Context) (tables []entities.TableInfo, err error) {
+	ctx, span := r.startSpan(ctx, "sqliteRepository.ListTables", "")
+	defer func() { endSpan(span, err) }()
+
+	// Число строк берется из статистики ANALYZE, если она собрана
+	var hasStats bool
+	err = r.db.GetContext(ctx, &hasStats, `
+		SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("check statistics: %w", err)
+	}
+
+	estimatedRows := "0"
+	if hasStats {
+		estimatedRows = `COALESCE((
+			SELECT CAST(substr(s.stat, 1, instr(s.stat || ' ', ' ') - 1) AS INTEGER)
+			FROM sqlite_stat1 s
+			WHERE s.tbl = m.name
+			LIMIT 1
+		), 0)`
+	}
+
+	err = r.db.SelectContext(ctx, &tables, fmt.Sprintf(`
+		SELECT
+			m.name AS name,
+			%s AS estimated_rows,
+			EXISTS (
+				SELECT 1 FROM pragma_index_list(m.name) il
+				JOIN pragma_index_info(il.name) ii
+				WHERE ii.seqno = 0 AND ii.name = 'created_at'
+			) AS has_date_index,
+			0 AS partitioned
+		FROM sqlite_master m
+		WHERE m.type = 'table'
+		AND m.name NOT LIKE 'sqlite_%%'
+		AND m.name NOT IN ('cleanup_locks', 'cleanup_audit_log', 'webhook_dead_letters')
+		AND EXISTS (SELECT 1 FROM pragma_table_info(m.name) c WHERE c.name = 'created_at')
+		ORDER BY 1
+	`, estimatedRows))
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// Вспомогательные функции
+
+// startSpan начинает спан обращения к базе данных
+func (r *sqliteRepository) startSpan(ctx context.Context, name, tableName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.sql.table", tableName))
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает спан, отмечая ошибку, если она произошла
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// formatTime приводит дату к текстовому формату SQLite в UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// splitTableName разделяет имя таблицы на схему (присоединенную базу) и имя таблицы
+func (r *sqliteRepository) splitTableName(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return "main", name
+}
+
+// quoteTableName экранирует имя таблицы двойными кавычками
+func (r *sqliteRepository) quoteTableName(name string) string {
+	schema, table := r.splitTableName(name)
+	return `"` + schema + `"."` + table + `"`
+}
+
+// isValidTableName проверяет, является ли имя таблицы безопасным для использования в SQL
+func (r *sqliteRepository) isValidTableName(name string) bool {
+	// Допускается не более одного разделителя схемы
+	if name == "" || strings.Count(name, ".") > 1 || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	// Только буквы, цифры, подчеркивания и разделитель
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '_' || char == '.') {
+			return false
+		}
+	}
+
+	return true
+}
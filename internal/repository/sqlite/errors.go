@@ -0,0 +1,24 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"errors"
+
+	"data-cleaner/internal/models/entities"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// classifyError помечает занятость базы другим писателем как entities.TransientError.
+// Остальные ошибки возвращаются без изменений и считаются фатальными.
+func classifyError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code {
+		case sqlite3.ErrBusy, sqlite3.ErrLocked:
+			return entities.TransientError{Code: sqliteErr.Code.Error(), Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build !cgo
+
+package sqlite
+
+// classifyError без cgo ничего не классифицирует: драйвер SQLite в такой сборке не работает
+func classifyError(err error) error {
+	return err
+}
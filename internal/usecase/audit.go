@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+
+	"go.uber.org/zap"
+)
+
+// Размер страницы при проверке журнала и ограничение выборки по умолчанию
+const (
+	auditPageSize     = 1000
+	auditDefaultLimit = 100
+)
+
+type auditUseCase struct {
+	repo   ports.AuditRepository
+	logger *zap.Logger
+}
+
+// NewAuditUseCase создает сервис чтения и проверки журнала аудита
+func NewAuditUseCase(repo ports.AuditRepository, logger *zap.Logger) ports.AuditUseCase {
+	return &auditUseCase{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListEntries возвращает записи журнала по фильтру
+func (uc *auditUseCase) ListEntries(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	if err := authorizeAuditRead(ctx); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 || filter.Limit > auditPageSize {
+		filter.Limit = auditDefaultLimit
+	}
+	return uc.repo.List(ctx, filter)
+}
+
+// Verify проходит всю цепочку и проверяет номера, ссылки на предыдущие записи и хеши
+func (uc *auditUseCase) Verify(ctx context.Context) (*entities.AuditVerification, error) {
+	if err := authorizeAuditRead(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &entities.AuditVerification{Valid: true}
+
+	var prev *entities.AuditEntry
+	filter := entities.AuditFilter{Limit: auditPageSize}
+	for {
+		entries, err := uc.repo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("read audit log: %w", err)
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			uc.checkEntry(result, prev, entry)
+			prev = entry
+			result.Checked++
+		}
+
+		if len(entries) < filter.Limit {
+			break
+		}
+		filter.AfterSeq = entries[len(entries)-1].Seq
+	}
+
+	if prev != nil {
+		result.LastSeq = prev.Seq
+		result.LastHash = prev.Hash
+	}
+	result.Valid = len(result.Problems) == 0
+
+	if !result.Valid {
+		uc.logger.Error("Audit log verification failed",
+			zap.Int("problems", len(result.Problems)),
+			zap.Int("checked", result.Checked))
+	}
+
+	return result, nil
+}
+
+// RecordRejected фиксирует запрос на очистку, отклоненный транспортным уровнем
+func (uc *auditUseCase) RecordRejected(ctx context.Context, req entities.CleanupRequest, mode string, reason error) {
+	entry := newAuditEntry(ctx, req, mode)
+	entry.Outcome = entities.AuditOutcomeRejected
+	entry.ErrorMessage = reason.Error()
+
+	if err := uc.repo.Append(context.WithoutCancel(ctx), &entry); err != nil {
+		uc.logger.Error("Failed to write audit entry",
+			zap.String("table", entry.TableName),
+			zap.String("outcome", entry.Outcome),
+			zap.Error(err))
+	}
+}
+
+// checkEntry сравнивает запись с предыдущей и пересчитывает ее хеш
+func (uc *auditUseCase) checkEntry(result *entities.AuditVerification, prev, entry *entities.AuditEntry) {
+	expectedSeq, expectedPrevHash := int64(1), ""
+	if prev != nil {
+		expectedSeq, expectedPrevHash = prev.Seq+1, prev.Hash
+	}
+
+	if entry.Seq != expectedSeq {
+		result.Problems = append(result.Problems, entities.AuditProblem{
+			Seq:    entry.Seq,
+			Kind:   entities.AuditProblemGap,
+			Detail: fmt.Sprintf("expected seq %d", expectedSeq),
+		})
+	}
+
+	if entry.PrevHash != expectedPrevHash {
+		result.Problems = append(result.Problems, entities.AuditProblem{
+			Seq:    entry.Seq,
+			Kind:   entities.AuditProblemBrokenChain,
+			Detail: "prev_hash does not match the previous entry",
+		})
+	}
+
+	if hash := entry.ComputeHash(); hash != entry.Hash {
+		result.Problems = append(result.Problems, entities.AuditProblem{
+			Seq:    entry.Seq,
+			Kind:   entities.AuditProblemHashMismatch,
+			Detail: "entry content does not match its hash",
+		})
+	}
+}
+
+// newAuditEntry заполняет запись журнала параметрами запроса и данными вызывающего
+func newAuditEntry(ctx context.Context, req entities.CleanupRequest, mode string) entities.AuditEntry {
+	actor := entities.AnonymousActor
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		actor = principal.ID
+	}
+
+	return entities.AuditEntry{
+		Actor:         actor,
+		RequestID:     entities.RequestIDFromContext(ctx),
+		Operation:     mode,
+		TableName:     req.TableName,
+		BeforeDate:    req.BeforeDate,
+		BatchSize:     req.BatchSize,
+		DryRun:        req.DryRun,
+		Parallelism:   req.Parallelism,
+		BatchTimeouts: req.Timeouts,
+		Strategy:      req.Strategy,
+		AllowTruncate: req.AllowTruncate,
+	}
+}
+
+// newRunEntry заполняет запись журнала о принятой очистке параметрами, с которыми она
+// выполняется: параллелизмом с учетом предела и таймаутами пакета с учетом настроек таблицы
+func (uc *cleanerUseCase) newRunEntry(ctx context.Context, req entities.CleanupRequest, mode string) entities.AuditEntry {
+	settings := uc.currentSettings()
+
+	entry := newAuditEntry(ctx, req, mode)
+	entry.Parallelism = min(req.Parallelism, settings.MaxParallelism)
+	entry.BatchTimeouts = req.Timeouts.Or(settings.BatchTimeouts.For(req.TableName))
+	return entry
+}
+
+// recordAudit добавляет запись в журнал аудита
+func (uc *cleanerUseCase) recordAudit(ctx context.Context, entry entities.AuditEntry) error {
+	if err := uc.audit.Append(ctx, &entry); err != nil {
+		uc.logger.Error("Failed to write audit entry",
+			zap.String("table", entry.TableName),
+			zap.String("outcome", entry.Outcome),
+			zap.Error(err))
+		return fmt.Errorf("write audit entry: %w", err)
+	}
+	return nil
+}
+
+// recordRejected фиксирует отклоненный запрос; ошибка записи не меняет ответ клиенту
+func (uc *cleanerUseCase) recordRejected(ctx context.Context, req entities.CleanupRequest, mode string, reason error) {
+	entry := newAuditEntry(ctx, req, mode)
+	entry.Outcome = entities.AuditOutcomeRejected
+	entry.ErrorMessage = reason.Error()
+	_ = uc.recordAudit(ctx, entry)
+}
+
+// recordResult фиксирует итог выполненной операции
+func (uc *cleanerUseCase) recordResult(ctx context.Context, entry entities.AuditEntry, result *entities.CleanupResult, err error) {
+	switch {
+	case result != nil:
+		entry.Outcome = result.Status
+		entry.RowsDeleted = result.RowsDeleted
+		entry.ErrorMessage = result.ErrorMessage
+		entry.Partitions = entities.NewAuditPartitions(result.Partitions)
+		entry.Method = result.Strategy
+	default:
+		entry.Outcome = entities.StatusFailed
+	}
+	if err != nil && entry.ErrorMessage == "" {
+		entry.ErrorMessage = err.Error()
+	}
+
+	// Итог записываем даже при отмененном контексте операции
+	_ = uc.recordAudit(context.WithoutCancel(ctx), entry)
+}
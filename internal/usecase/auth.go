@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// authorize проверяет права клиента из контекста на выполнение запроса.
+// Если клиент в контексте отсутствует, аутентификация отключена и проверка не выполняется.
+func (uc *cleanerUseCase) authorize(ctx context.Context, req entities.CleanupRequest, mode string) error {
+	principal, ok := entities.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch {
+	case !principal.Scope.AllowsMode(mode):
+		err = entities.NewForbiddenError(fmt.Sprintf("key %s is not allowed to run %s cleanups", principal.ID, mode))
+	case !principal.Scope.AllowsTable(req.TableName):
+		err = entities.NewForbiddenError(fmt.Sprintf("key %s is not allowed to clean table %s", principal.ID, req.TableName))
+	case !principal.Scope.AllowsCutoff(req.BeforeDate, time.Now()):
+		err = entities.NewForbiddenError(fmt.Sprintf("key %s may not delete data newer than %s",
+			principal.ID, principal.Scope.MinRetention))
+	}
+
+	if err != nil {
+		uc.logger.Warn("Cleanup request forbidden",
+			zap.String("principal", principal.ID),
+			zap.String("table", req.TableName),
+			zap.String("mode", mode),
+			zap.Error(err))
+	}
+
+	return err
+}
+
+// canReadTask проверяет, что клиент из контекста может читать задачу: запущенную им самим
+// или любую, если у него есть права администратора
+func canReadTask(ctx context.Context, task *taskState) bool {
+	principal, ok := entities.PrincipalFromContext(ctx)
+	if !ok {
+		return true
+	}
+	return principal.Scope.Admin || principal.ID == task.owner
+}
+
+// authorizeAuditRead проверяет, что клиент из контекста может читать журнал аудита
+func authorizeAuditRead(ctx context.Context) error {
+	principal, ok := entities.PrincipalFromContext(ctx)
+	if !ok || principal.Scope.Admin {
+		return nil
+	}
+	return entities.NewForbiddenError(fmt.Sprintf("key %s is not allowed to read the audit log", principal.ID))
+}
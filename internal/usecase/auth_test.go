@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// memoryAudit хранит записи журнала аудита в памяти
+type memoryAudit struct {
+	entries []entities.AuditEntry
+}
+
+func (a *memoryAudit) Append(_ context.Context, entry *entities.AuditEntry) error {
+	entry.Seq = int64(len(a.entries) + 1)
+	a.entries = append(a.entries, *entry)
+	return nil
+}
+
+func (a *memoryAudit) List(context.Context, entities.AuditFilter) ([]entities.AuditEntry, error) {
+	return a.entries, nil
+}
+
+func withKey(id string, admin bool) context.Context {
+	return entities.WithPrincipal(context.Background(), &entities.Principal{
+		ID:    id,
+		Scope: entities.AuthScope{Tables: []string{"*.*"}, Admin: admin},
+	})
+}
+
+func TestTaskReadsAreScopedToOwner(t *testing.T) {
+	uc := NewCleanerUseCase(nil, zap.NewNop()).(*cleanerUseCase)
+
+	task := newTaskState("events", entities.StatusInProgress)
+	task.id = "task-1"
+	task.owner = "ops"
+	uc.activeTasks[task.id] = task
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		wantOK bool
+	}{
+		{"owner", withKey("ops", false), true},
+		{"other key", withKey("billing", false), false},
+		{"admin key", withKey("root", true), true},
+		{"authentication disabled", context.Background(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.GetCleanupStatus(tt.ctx, task.id)
+			_, cancel, subErr := uc.SubscribeProgress(tt.ctx, task.id)
+			if cancel != nil {
+				cancel()
+			}
+
+			var notFound entities.NotFoundError
+			for _, err := range []error{err, subErr} {
+				if tt.wantOK && err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+				if !tt.wantOK && !errors.As(err, &notFound) {
+					t.Errorf("error = %v, want not found", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogRequiresAdmin(t *testing.T) {
+	repo := &memoryAudit{}
+	uc := NewAuditUseCase(repo, zap.NewNop())
+	uc.RecordRejected(context.Background(), entities.CleanupRequest{TableName: "events"}, entities.ModeSync, errors.New("denied"))
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		wantForbidden bool
+	}{
+		{"regular key", withKey("ops", false), true},
+		{"admin key", withKey("root", true), false},
+		{"authentication disabled", context.Background(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, listErr := uc.ListEntries(tt.ctx, entities.AuditFilter{})
+			_, verifyErr := uc.Verify(tt.ctx)
+
+			var forbidden entities.ForbiddenError
+			for _, err := range []error{listErr, verifyErr} {
+				if got := errors.As(err, &forbidden); got != tt.wantForbidden {
+					t.Errorf("error = %v, want forbidden %v", err, tt.wantForbidden)
+				}
+			}
+		})
+	}
+}
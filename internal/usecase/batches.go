@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// deleteRange удаляет пакетами подходящие строки таблицы или ее секции target, продвигая
+// keyset-курсор между пакетами. Когда диапазон исчерпан, он один раз проходится с начала:
+// SKIP LOCKED мог пропустить строки, заблокированные другими транзакциями.
+// onBatch получает число удаленных строк и возвращает общий итог задачи.
+// При ошибке возвращается статус, которым должна завершиться задача.
+func (uc *cleanerUseCase) deleteRange(ctx context.Context, req entities.CleanupRequest, target string, task *taskState, onBatch func(deleted int) int, gate *sync.Mutex) (int, string, error) {
+	fields := []zap.Field{zap.String("table", req.TableName)}
+	if target != req.TableName {
+		fields = append(fields, zap.String("partition", target))
+	}
+
+	deleted := 0
+	var cursor entities.BatchCursor
+	sweeping := false
+	for {
+		// Если окно обслуживания закрылось, приостанавливаемся на границе пакета
+		if !uc.currentSettings().MaintenanceWindows.IsOpen(req.TableName, time.Now()) {
+			if err := uc.pauseForWindow(ctx, req.TableName, task, gate); err != nil {
+				return deleted, entities.StatusCanceled, err
+			}
+		}
+
+		// Удаляем пакет данных, повторяя его после временных ошибок
+		batch, batchDuration, err := uc.deleteBatch(ctx, req, target, cursor, task)
+		if err != nil {
+			uc.logger.Error("Error deleting batch", append(fields, zap.Error(err))...)
+			return deleted, failureStatus(err), err
+		}
+
+		uc.metrics.ObserveBatch(req.TableName, batch.Deleted, batchDuration)
+		deleted += batch.Deleted
+		total := onBatch(batch.Deleted)
+		uc.logger.Info("Batch deleted", append(fields,
+			zap.Int("deleted_count", batch.Deleted),
+			zap.Int("total_deleted", total))...)
+
+		cursor = batch.Next
+		if batch.Exhausted {
+			// Курсор не сдвигался с начала диапазона - повторный проход ничего не даст
+			if sweeping || cursor.IsZero() {
+				return deleted, entities.StatusCompleted, nil
+			}
+			uc.logger.Debug("Cursor range exhausted, sweeping from the start", fields...)
+			cursor = entities.BatchCursor{}
+			sweeping = true
+		}
+
+		// Небольшая пауза между пакетами, чтобы снизить нагрузку
+		select {
+		case <-time.After(uc.currentSettings().BatchPause):
+			// Продолжаем выполнение
+		case <-ctx.Done():
+			// Контекст был отменен
+			return deleted, entities.StatusCanceled, ctx.Err()
+		}
+	}
+}
+
+// pauseForWindow приостанавливает очистку до открытия окна обслуживания.
+// При параллельной очистке окна ждет один исполнитель, остальные дожидаются его на gate.
+// Синхронная очистка не ждет окна и останавливается с ошибкой WindowClosedError.
+func (uc *cleanerUseCase) pauseForWindow(ctx context.Context, tableName string, task *taskState, gate *sync.Mutex) error {
+	gate.Lock()
+	defer gate.Unlock()
+
+	// Окно могло открыться, пока ждал другой исполнитель
+	schedule := uc.currentSettings().MaintenanceWindows
+	now := time.Now()
+	if schedule.IsOpen(tableName, now) {
+		return nil
+	}
+
+	if task.sync {
+		uc.logger.Info("Maintenance window closed, stopping synchronous cleanup",
+			zap.String("table", tableName),
+			zap.Int("total_deleted", task.snapshot().RowsDeleted))
+		trace.SpanFromContext(ctx).AddEvent("maintenance window closed")
+		return entities.WindowClosedError{Table: tableName, NextOpen: schedule.NextOpen(tableName, now)}
+	}
+
+	uc.logger.Info("Maintenance window closed, pausing cleanup",
+		zap.String("table", tableName),
+		zap.Int("total_deleted", task.snapshot().RowsDeleted))
+	trace.SpanFromContext(ctx).AddEvent("maintenance window closed")
+	task.emit(entities.ProgressEvent{Type: entities.EventPaused, Reason: "maintenance window closed"})
+
+	if err := uc.waitForWindow(ctx, tableName, task); err != nil {
+		return err
+	}
+
+	uc.logger.Info("Maintenance window opened, resuming cleanup",
+		zap.String("table", tableName))
+	trace.SpanFromContext(ctx).AddEvent("maintenance window opened")
+	task.setStatus(entities.StatusInProgress)
+	task.emit(entities.ProgressEvent{Type: entities.EventResumed, Reason: "maintenance window opened"})
+	return nil
+}
@@ -2,194 +2,583 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"data-cleaner/internal/models/entities"
 	"data-cleaner/internal/models/ports"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("data-cleaner/usecase")
+
+// Предел точного подсчета строк при оценке объема; для больших таблиц используется статистика
+const estimateCountLimit = 1_000_000
+
+// Сколько хранится состояние завершенной асинхронной задачи
+const taskRetention = 1 * time.Hour
+
 type cleanerUseCase struct {
 	repo            ports.CleanerRepository
 	logger          *zap.Logger
+	settings        atomic.Pointer[entities.RuntimeSettings]
+	metrics         ports.CleanerMetrics
+	audit           ports.AuditRepository
+	notifier        ports.Notifier
+	copySwap        ports.CopySwapRepository
+	runningTasks    atomic.Int64
+	queuedTasks     atomic.Int64
+	events          *eventHub
+	idempotency     *idempotencyStore
 	activeTasksLock sync.RWMutex
-	activeTasks     map[string]*entities.CleanupResult
+	activeTasks     map[string]*taskState
 }
 
 // NewCleanerUseCase создает новый экземпляр сервиса очистки данных
-func NewCleanerUseCase(repo ports.CleanerRepository, logger *zap.Logger) ports.CleanerUseCase {
-	return &cleanerUseCase{
+func NewCleanerUseCase(repo ports.CleanerRepository, logger *zap.Logger, opts ...Option) ports.CleanerUseCase {
+	uc := &cleanerUseCase{
 		repo:        repo,
 		logger:      logger,
-		activeTasks: make(map[string]*entities.CleanupResult),
+		metrics:     noopMetrics{},
+		audit:       noopAudit{},
+		notifier:    noopNotifier{},
+		events:      newEventHub(),
+		idempotency: newIdempotencyStore(),
+		activeTasks: make(map[string]*taskState),
 	}
+
+	defaults := entities.DefaultRuntimeSettings()
+	uc.settings.Store(&defaults)
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
 }
 
 // CleanTable удаляет старые данные из указанной таблицы
 func (uc *cleanerUseCase) CleanTable(ctx context.Context, req entities.CleanupRequest) (*entities.CleanupResult, error) {
-	// Валидируем запрос
-	if err := req.Validate(); err != nil {
+	settings := uc.currentSettings()
+	req = withDefaults(req, settings)
+	mode := req.Mode(false)
+
+	// Ограничиваем время синхронной очистки
+	if settings.MaxRequestTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, settings.MaxRequestTime)
+		defer cancel()
+	}
+
+	// Проверяем запрос, права клиента и политику таблиц
+	if err := uc.admit(ctx, req, mode); err != nil {
+		return nil, err
+	}
+
+	// Уведомления о завершении отправляются только для асинхронных задач
+	if req.CallbackURL != "" {
+		uc.recordRejected(ctx, req, mode, entities.ErrCallbackNotSupported)
+		return nil, entities.ErrCallbackNotSupported
+	}
+
+	// Синхронная очистка не ждет открытия окна обслуживания: клиенту сообщается, когда повторить запрос
+	if now := time.Now(); !req.DryRun && !settings.MaintenanceWindows.IsOpen(req.TableName, now) {
+		err := entities.WindowClosedError{
+			Table:      req.TableName,
+			NextOpen:   settings.MaintenanceWindows.NextOpen(req.TableName, now),
+			NotStarted: true,
+		}
+		uc.recordRejected(ctx, req, mode, err)
+		return nil, err
+	}
+
+	// Операция не начинается, пока факт ее запуска не записан в журнал аудита
+	entry := uc.newRunEntry(ctx, req, mode)
+	started := entry
+	started.Outcome = entities.AuditOutcomeStarted
+	if err := uc.recordAudit(ctx, started); err != nil {
 		return nil, err
 	}
 
+	task := newTaskState(req.TableName, entities.StatusInProgress)
+	task.sync = true
+	result, err := uc.execute(ctx, req, task)
+	uc.recordResult(ctx, entry, result, err)
+
+	return result, err
+}
+
+// admit проверяет запрос, поддержку выбранного способа очистки, права клиента и политику
+// таблиц, фиксируя отказ в журнале аудита
+func (uc *cleanerUseCase) admit(ctx context.Context, req entities.CleanupRequest, mode string) error {
+	// Валидируем запрос
+	err := req.Validate()
+	if err == nil && req.Strategy == entities.StrategyCopySwap && uc.copySwap == nil {
+		err = entities.ErrCopySwapNotSupported
+	}
+	if err == nil {
+		err = uc.authorize(ctx, req, mode)
+	}
+	if err == nil {
+		err = uc.checkTablePolicy(ctx, req, mode)
+	}
+
+	if err != nil {
+		uc.recordRejected(ctx, req, mode, err)
+	}
+	return err
+}
+
+// execute выполняет очистку внутри спана трассировки
+func (uc *cleanerUseCase) execute(ctx context.Context, req entities.CleanupRequest, task *taskState) (*entities.CleanupResult, error) {
+	ctx, span := tracer.Start(ctx, "cleanerUseCase.CleanTable", trace.WithAttributes(
+		attribute.String("db.sql.table", req.TableName),
+		attribute.String("cleanup.before_date", req.BeforeDate.Format(time.RFC3339)),
+		attribute.Int("cleanup.batch_size", req.BatchSize),
+	))
+	defer span.End()
+
+	result, err := uc.run(ctx, req, task)
+	if result != nil {
+		span.SetAttributes(
+			attribute.Int("cleanup.rows_deleted", result.RowsDeleted),
+			attribute.String("cleanup.status", result.Status))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return result, err
+}
+
+// run проверяет таблицу, захватывает блокировку и удаляет данные пакетами,
+// отражая ход выполнения в состоянии задачи
+func (uc *cleanerUseCase) run(ctx context.Context, req entities.CleanupRequest, task *taskState) (*entities.CleanupResult, error) {
 	// Проверяем существование таблицы и индекса
 	if err := uc.repo.ValidateTable(ctx, req.TableName); err != nil {
 		return nil, fmt.Errorf("table validation failed: %w", err)
 	}
 
+	// Пробный запуск только подсчитывает строки, которые были бы удалены
+	if req.DryRun {
+		return uc.dryRun(ctx, req, task)
+	}
+
 	// Пытаемся получить блокировку для таблицы
 	acquired, unlock, err := uc.repo.TryAcquireLock(ctx, req.TableName)
 	if err != nil {
+		uc.metrics.IncLockFailures(req.TableName)
 		return nil, fmt.Errorf("lock acquisition failed: %w", err)
 	}
 
 	if !acquired {
-		return nil, fmt.Errorf("another process is already cleaning table %s", req.TableName)
+		uc.metrics.IncLockFailures(req.TableName)
+		return nil, entities.LockConflictError{Table: req.TableName}
 	}
 	defer unlock()
 
+	uc.addRunningTasks(1)
+	defer uc.addRunningTasks(-1)
+
 	// Логируем начало операции
 	uc.logger.Info("Starting data cleanup",
 		zap.String("table", req.TableName),
 		zap.Time("before_date", req.BeforeDate),
 		zap.Int("batch_size", req.BatchSize))
 
-	startTime := time.Now()
-	result := &entities.CleanupResult{
-		TableName:   req.TableName,
-		Status:      "in_progress",
-		RowsDeleted: 0,
+	// Оцениваем объем работы для расчета процента выполнения и оставшегося времени
+	estimated, err := uc.repo.EstimateRows(ctx, req.TableName, req.BeforeDate, estimateCountLimit)
+	if err != nil {
+		uc.logger.Warn("Failed to estimate rows to delete, progress will not be reported",
+			zap.String("table", req.TableName),
+			zap.Error(err))
 	}
+	// Оценка ниже предела получена точным подсчетом, и с ней можно сверить итог
+	estimateExact := err == nil && estimated < estimateCountLimit
 
-	// Удаляем данные небольшими порциями
-	totalDeleted := 0
-	for {
-		// Устанавливаем таймаут для каждой итерации
-		iterCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		defer cancel()
+	startTime := time.Now()
+	task.update(func(r *entities.CleanupResult) {
+		r.Status = entities.StatusInProgress
+		r.RowsDeleted = 0
+		r.RowsEstimated = estimated
+		r.Strategy = entities.StrategyBatchDelete
+	})
+
+	finish := func(status string, errMsg string) *entities.CleanupResult {
+		task.update(func(r *entities.CleanupResult) {
+			r.Status = status
+			r.ErrorMessage = errMsg
+			r.ElapsedTime = time.Since(startTime)
+			r.UpdateProgress(r.ElapsedTime)
+			if status == entities.StatusCompleted {
+				r.PercentComplete = 100
+				r.ETA = 0
+			}
+		})
+		return task.snapshot()
+	}
 
-		// Удаляем пакет данных
-		deleted, err := uc.repo.DeleteBatch(iterCtx, req.TableName, req.BeforeDate, req.BatchSize)
-		if err != nil {
-			uc.logger.Error("Error deleting batch",
-				zap.String("table", req.TableName),
-				zap.Error(err))
+	// Ход выполнения суммируется по всем пакетам, в том числе из параллельных исполнителей
+	onBatch := func(deleted int) int {
+		var total int
+		task.update(func(r *entities.CleanupResult) {
+			r.RowsDeleted += deleted
+			total = r.RowsDeleted
+			r.ElapsedTime = time.Since(startTime)
+			r.UpdateProgress(r.ElapsedTime)
+		})
+		task.emit(entities.ProgressEvent{
+			Type:          entities.EventBatchCompleted,
+			BatchRows:     deleted,
+			RowsPerSecond: task.snapshot().RowsPerSecond,
+		})
+		return total
+	}
 
-			result.Status = "failed"
-			result.ErrorMessage = err.Error()
-			result.ElapsedTime = time.Since(startTime)
-			return result, fmt.Errorf("batch deletion failed: %w", err)
+	// Таблица, все записи которой устарели, очищается целиком, если запрос это разрешает
+	var truncation entities.TruncateResult
+	if req.AllowTruncate {
+		if truncation, err = uc.truncate(ctx, req, req.TableName); err != nil {
+			return finish(entities.StatusCanceled, ""), err
 		}
+		task.update(func(r *entities.CleanupResult) {
+			r.StrategyReason = truncation.Reason
+		})
+	}
 
-		totalDeleted += deleted
-		uc.logger.Info("Batch deleted",
-			zap.String("table", req.TableName),
-			zap.Int("deleted_count", deleted),
-			zap.Int("total_deleted", totalDeleted))
-
-		// Если удалили меньше, чем размер пакета, значит данных больше нет
-		if deleted < req.BatchSize {
-			break
+	// Таблица очищается заменой, если это запрошено; секционированная таблица при заданном
+	// параллелизме или разрешенном TRUNCATE очищается по секциям
+	var status string
+	if truncation.Truncated {
+		task.update(func(r *entities.CleanupResult) {
+			r.Strategy = entities.StrategyTruncate
+		})
+		onBatch(truncation.Rows)
+		status = entities.StatusCompleted
+	} else if req.Strategy == entities.StrategyCopySwap {
+		task.update(func(r *entities.CleanupResult) {
+			r.Strategy = entities.StrategyCopySwap
+		})
+		status, err = uc.copyAndSwap(ctx, req, task, onBatch)
+	} else if partitions := uc.eligiblePartitions(ctx, req); len(partitions) > 0 {
+		status, err = uc.deletePartitions(ctx, req, partitions, task, onBatch)
+	} else {
+		_, status, err = uc.deleteRange(ctx, req, req.TableName, task, onBatch, &sync.Mutex{})
+	}
+	if err != nil {
+		// Синхронную очистку остановило закрытие окна: удаленное сохраняется, а причина
+		// остановки попадает в результат
+		var windowErr entities.WindowClosedError
+		if errors.As(err, &windowErr) {
+			result := finish(status, windowErr.Error())
+			windowErr.RowsDeleted = result.RowsDeleted
+			return result, windowErr
 		}
-
-		// Небольшая пауза между пакетами, чтобы снизить нагрузку
-		select {
-		case <-time.After(100 * time.Millisecond):
-			// Продолжаем выполнение
-		case <-ctx.Done():
-			// Контекст был отменен
-			result.Status = "canceled"
-			result.RowsDeleted = totalDeleted
-			result.ElapsedTime = time.Since(startTime)
-			return result, ctx.Err()
+		if status == entities.StatusCanceled {
+			return finish(status, ""), err
 		}
+		if req.Strategy == entities.StrategyCopySwap {
+			return finish(status, err.Error()), fmt.Errorf("copy and swap failed: %w", err)
+		}
+		return finish(status, err.Error()), fmt.Errorf("batch deletion failed: %w", err)
 	}
 
-	elapsedTime := time.Since(startTime)
+	// Проверяем, что записей старше before_date не осталось: их могли вставить во время
+	// очистки или пропустить при замене таблицы
+	verification := uc.verify(ctx, req, task.snapshot().RowsDeleted, estimated, estimateExact)
+	task.update(func(r *entities.CleanupResult) {
+		r.Verified = &verification
+	})
+	status = entities.StatusCompleted
+	if verification.RowsRemaining > 0 {
+		status = entities.StatusCompletedWithLeftovers
+	}
+
+	result := finish(status, "")
 	uc.logger.Info("Cleanup completed",
 		zap.String("table", req.TableName),
-		zap.Int("total_deleted", totalDeleted),
-		zap.Duration("duration", elapsedTime))
-
-	result.Status = "completed"
-	result.RowsDeleted = totalDeleted
-	result.ElapsedTime = elapsedTime
+		zap.String("status", result.Status),
+		zap.Int("total_deleted", result.RowsDeleted),
+		zap.Int("rows_remaining", verification.RowsRemaining),
+		zap.String("strategy", result.Strategy),
+		zap.Duration("duration", result.ElapsedTime))
 
 	return result, nil
 }
 
-// StartAsyncCleanup запускает асинхронную очистку и возвращает идентификатор задачи
+// dryRun подсчитывает строки, подлежащие удалению, ничего не удаляя
+func (uc *cleanerUseCase) dryRun(ctx context.Context, req entities.CleanupRequest, task *taskState) (*entities.CleanupResult, error) {
+	startTime := time.Now()
+
+	matched, err := uc.repo.CountRows(ctx, req.TableName, req.BeforeDate)
+	if err != nil {
+		task.update(func(r *entities.CleanupResult) {
+			r.Status = entities.StatusFailed
+			r.ErrorMessage = err.Error()
+			r.ElapsedTime = time.Since(startTime)
+		})
+		return task.snapshot(), fmt.Errorf("count rows failed: %w", err)
+	}
+
+	task.update(func(r *entities.CleanupResult) {
+		r.Status = entities.StatusDryRun
+		r.RowsMatched = matched
+		r.ElapsedTime = time.Since(startTime)
+	})
+
+	uc.logger.Info("Dry run completed",
+		zap.String("table", req.TableName),
+		zap.Time("before_date", req.BeforeDate),
+		zap.Int("rows_matched", matched))
+
+	return task.snapshot(), nil
+}
+
+// StartAsyncCleanup запускает асинхронную очистку и возвращает идентификатор задачи.
+// Если в контексте есть ключ идемпотентности, повторная отправка того же запроса
+// возвращает исходную задачу, а отправка другого запроса с тем же ключом - ошибку конфликта.
 func (uc *cleanerUseCase) StartAsyncCleanup(ctx context.Context, req entities.CleanupRequest) (string, error) {
-	// Валидируем запрос
-	if err := req.Validate(); err != nil {
+	key := entities.IdempotencyKeyFromContext(ctx)
+	ttl := uc.currentSettings().IdempotencyKeyTTL
+	if key == "" || ttl <= 0 {
+		return uc.startAsync(ctx, req, time.Time{})
+	}
+
+	if err := entities.ValidateIdempotencyKey(key); err != nil {
 		return "", err
 	}
 
+	// Отпечаток считается до подстановки значений по умолчанию, то есть по телу запроса
+	scope := idempotencyScope(ctx, key)
+	fingerprint := req.Fingerprint()
+	rec, existing, err := uc.idempotency.reserve(ctx, scope, fingerprint, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	if existing {
+		if rec.fingerprint != fingerprint {
+			uc.logger.Warn("Idempotency key reused with a different request",
+				zap.String("table", req.TableName),
+				zap.String("task_id", rec.taskID))
+			return "", entities.IdempotencyConflictError{Key: key}
+		}
+
+		uc.logger.Info("Returning existing task for idempotency key",
+			zap.String("table", req.TableName),
+			zap.String("task_id", rec.taskID))
+		return rec.taskID, nil
+	}
+
+	taskID, err := uc.startAsync(ctx, req, rec.expiresAt)
+	if err != nil {
+		uc.idempotency.release(scope, rec)
+		return "", err
+	}
+	uc.idempotency.complete(scope, rec, taskID)
+
+	return taskID, nil
+}
+
+// startAsync создает асинхронную задачу. Состояние задачи хранится не меньше taskRetention
+// после завершения и не меньше, чем до retainUntil, пока действует ключ идемпотентности.
+func (uc *cleanerUseCase) startAsync(ctx context.Context, req entities.CleanupRequest, retainUntil time.Time) (string, error) {
+	req = withDefaults(req, uc.currentSettings())
+	mode := req.Mode(true)
+
+	// Проверяем запрос, права клиента и политику таблиц
+	if err := uc.admit(ctx, req, mode); err != nil {
+		return "", err
+	}
+
+	// Адрес уведомления проверяется до создания задачи
+	if req.CallbackURL != "" {
+		if err := uc.notifier.CheckCallbackURL(req.CallbackURL); err != nil {
+			uc.recordRejected(ctx, req, mode, err)
+			return "", err
+		}
+	}
+
 	// Генерируем уникальный ID для задачи
 	taskID := uuid.New().String()
 
-	// Создаем начальный результат
-	result := &entities.CleanupResult{
-		TableName: req.TableName,
-		Status:    "pending",
+	// Задача не создается, пока факт ее приема не записан в журнал аудита
+	entry := uc.newRunEntry(ctx, req, mode)
+	entry.TaskID = taskID
+	accepted := entry
+	accepted.Outcome = entities.AuditOutcomeAccepted
+	if err := uc.recordAudit(ctx, accepted); err != nil {
+		return "", err
+	}
+
+	// Создаем начальное состояние задачи
+	task := newTaskState(req.TableName, entities.StatusPending)
+	task.id = taskID
+	task.publish = uc.events.publish
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		task.owner = principal.ID
+	}
+	task.retainUntil = retainUntil
+	if !req.DryRun && !uc.currentSettings().MaintenanceWindows.IsOpen(req.TableName, time.Now()) {
+		task.setStatus(entities.StatusWaitingForWindow)
 	}
 
 	// Сохраняем задачу в списке активных
 	uc.activeTasksLock.Lock()
-	uc.activeTasks[taskID] = result
+	uc.activeTasks[taskID] = task
 	uc.activeTasksLock.Unlock()
+	uc.addQueuedTasks(1)
 
-	// Создаем новый контекст с таймаутом для асинхронной операции
-	cleanupCtx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
+	// Асинхронная операция продолжает трассу запроса, который ее запустил
+	spanCtx := trace.SpanContextFromContext(ctx)
 
 	// Запускаем очистку в отдельной горутине
 	go func() {
+		// Ждем открытия окна обслуживания; таймаут операции отсчитывается после него.
+		// Пробному запуску окно обслуживания не требуется.
+		if !req.DryRun {
+			if err := uc.waitForWindow(context.Background(), req.TableName, task); err != nil {
+				uc.addQueuedTasks(-1)
+				task.update(func(r *entities.CleanupResult) {
+					r.Status = entities.StatusFailed
+					r.ErrorMessage = err.Error()
+				})
+				uc.finishAsync(context.Background(), taskID, req, entry, task, err)
+				return
+			}
+		}
+
+		// Создаем новый контекст с таймаутом для асинхронной операции
+		cleanupCtx, cancel := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), spanCtx), 1*time.Hour)
 		defer cancel()
 
 		// Обновляем статус
-		result.Status = "in_progress"
+		uc.addQueuedTasks(-1)
+		task.setStatus(entities.StatusInProgress)
 
 		// Выполняем очистку
-		cleanResult, err := uc.CleanTable(cleanupCtx, req)
-
-		// Обновляем результат
-		uc.activeTasksLock.Lock()
+		cleanResult, err := uc.execute(cleanupCtx, req, task)
 		if err != nil && cleanResult == nil {
 			// Если произошла ошибка и результат не был возвращен
-			result.Status = "failed"
-			result.ErrorMessage = err.Error()
-		} else {
-			// Копируем данные из результата
-			*result = *cleanResult
+			task.update(func(r *entities.CleanupResult) {
+				r.Status = entities.StatusFailed
+				r.ErrorMessage = err.Error()
+			})
 		}
-		uc.activeTasksLock.Unlock()
-
-		// Очищаем информацию о задаче через некоторое время
-		time.AfterFunc(1*time.Hour, func() {
-			uc.activeTasksLock.Lock()
-			delete(uc.activeTasks, taskID)
-			uc.activeTasksLock.Unlock()
-		})
+		uc.finishAsync(cleanupCtx, taskID, req, entry, task, err)
 	}()
 
 	return taskID, nil
 }
 
+// finishAsync фиксирует итог асинхронной задачи в журнале аудита, отправляет уведомления
+// и планирует удаление задачи из списка активных
+func (uc *cleanerUseCase) finishAsync(ctx context.Context, taskID string, req entities.CleanupRequest,
+	entry entities.AuditEntry, task *taskState, err error) {
+	result := task.snapshot()
+	uc.recordResult(ctx, entry, result, err)
+
+	task.emit(entities.ProgressEvent{Type: entities.EventFinished, Result: result})
+
+	uc.notifier.Notify(context.WithoutCancel(ctx), entities.WebhookEvent{
+		ID:         uuid.New().String(),
+		Type:       entities.WebhookEventType(result.Status),
+		TaskID:     taskID,
+		OccurredAt: time.Now().UTC(),
+		Result:     *result,
+	}, req.CallbackURL)
+
+	// Очищаем информацию о задаче через некоторое время
+	retention := taskRetention
+	if untilKeyExpires := time.Until(task.retainUntil); untilKeyExpires > retention {
+		retention = untilKeyExpires
+	}
+	time.AfterFunc(retention, func() {
+		uc.activeTasksLock.Lock()
+		delete(uc.activeTasks, taskID)
+		uc.activeTasksLock.Unlock()
+	})
+}
+
 // GetCleanupStatus возвращает статус операции очистки по идентификатору
 func (uc *cleanerUseCase) GetCleanupStatus(ctx context.Context, taskID string) (*entities.CleanupResult, error) {
+	task, err := uc.lookupTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Возвращаем копию результата
+	return task.snapshot(), nil
+}
+
+// lookupTask находит задачу, доступную клиенту из контекста. Чужие задачи не отличаются
+// от несуществующих, чтобы не раскрывать их идентификаторы.
+func (uc *cleanerUseCase) lookupTask(ctx context.Context, taskID string) (*taskState, error) {
 	uc.activeTasksLock.RLock()
-	defer uc.activeTasksLock.RUnlock()
+	task, exists := uc.activeTasks[taskID]
+	uc.activeTasksLock.RUnlock()
+	if !exists || !canReadTask(ctx, task) {
+		return nil, entities.NotFoundError{Resource: entities.ResourceTask, Name: taskID}
+	}
+	return task, nil
+}
 
-	result, exists := uc.activeTasks[taskID]
-	if !exists {
-		return nil, fmt.Errorf("task with ID %s not found", taskID)
+// SubscribeProgress подписывает на события хода выполнения задачи.
+// Если задача уже завершена, канал содержит только итоговое событие.
+func (uc *cleanerUseCase) SubscribeProgress(ctx context.Context, taskID string) (<-chan entities.ProgressEvent, func(), error) {
+	task, err := uc.lookupTask(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Возвращаем копию результата
-	resultCopy := *result
-	return &resultCopy, nil
+	// Подписываемся до чтения состояния, чтобы не пропустить завершение между ними
+	ch, cancel := uc.events.subscribe(taskID)
+
+	snapshot := task.snapshot()
+	if entities.IsTerminalStatus(snapshot.Status) {
+		cancel()
+		final := make(chan entities.ProgressEvent, 1)
+		final <- entities.ProgressEvent{
+			Type:        entities.EventFinished,
+			TaskID:      taskID,
+			Time:        time.Now().UTC(),
+			Status:      snapshot.Status,
+			RowsDeleted: snapshot.RowsDeleted,
+			Result:      snapshot,
+		}
+		close(final)
+		return final, func() {}, nil
+	}
+
+	return ch, cancel, nil
+}
+
+// GetTaskStats возвращает количество выполняемых и ожидающих задач
+func (uc *cleanerUseCase) GetTaskStats(ctx context.Context) entities.TaskStats {
+	return entities.TaskStats{
+		Running: int(uc.runningTasks.Load()),
+		Queued:  int(uc.queuedTasks.Load()),
+	}
+}
+
+// addRunningTasks изменяет счетчик выполняемых задач и соответствующую метрику
+func (uc *cleanerUseCase) addRunningTasks(delta int) {
+	uc.runningTasks.Add(int64(delta))
+	uc.metrics.AddRunningTasks(delta)
+}
+
+// addQueuedTasks изменяет счетчик ожидающих задач и соответствующую метрику
+func (uc *cleanerUseCase) addQueuedTasks(delta int) {
+	uc.queuedTasks.Add(int64(delta))
+	uc.metrics.AddQueuedTasks(delta)
 }
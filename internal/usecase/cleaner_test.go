@@ -0,0 +1,607 @@
+//go:build cgo
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/config"
+	"data-cleaner/internal/pkg/sqlite"
+	sqliterepo "data-cleaner/internal/repository/sqlite"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Тесты сервиса работают с репозиторием SQLite в памяти; драйвер требует сборки с cgo
+
+// Граница очистки и даты тестовых записей
+var (
+	testBeforeDate = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	testOldDate    = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNewDate    = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newTestDB открывает базу в памяти с таблицей events, в которой old записей старше
+// testBeforeDate и fresh записей новее нее
+func newTestDB(t *testing.T, old, fresh int) *sqlx.DB {
+	t.Helper()
+
+	// Каждое соединение с :memory: открывает свою базу, поэтому оно одно
+	cfg := &config.Config{DBPath: ":memory:", DBMaxOpenConns: 1, DBMaxIdleConns: 1}
+	db, err := sqlite.NewSQLiteDB(context.Background(), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	db.MustExec("CREATE TABLE events (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL)")
+	db.MustExec("CREATE INDEX events_created_at ON events (created_at, id)")
+	for i := 0; i < old; i++ {
+		insertEvent(t, db, testOldDate.Add(time.Duration(i)*time.Hour))
+	}
+	for i := 0; i < fresh; i++ {
+		insertEvent(t, db, testNewDate.Add(time.Duration(i)*time.Hour))
+	}
+
+	return db
+}
+
+func insertEvent(t *testing.T, db *sqlx.DB, createdAt time.Time) {
+	t.Helper()
+
+	if _, err := db.Exec("INSERT INTO events (created_at) VALUES (?)", createdAt); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+}
+
+func countEvents(t *testing.T, db *sqlx.DB) int {
+	t.Helper()
+
+	var n int
+	if err := db.Get(&n, "SELECT count(*) FROM events"); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	return n
+}
+
+// newTestUseCase создает сервис без пауз между пакетами с журналом аудита в той же базе
+func newTestUseCase(db *sqlx.DB, repo ports.CleanerRepository, opts ...Option) *cleanerUseCase {
+	settings := entities.DefaultRuntimeSettings()
+	settings.BatchPause = 0
+	settings.MaxRequestTime = 10 * time.Second
+
+	opts = append([]Option{WithSettings(settings), WithAuditLog(sqliterepo.NewAuditRepository(db, zap.NewNop()))}, opts...)
+	return NewCleanerUseCase(repo, zap.NewNop(), opts...).(*cleanerUseCase)
+}
+
+// auditOutcomes возвращает исходы записей журнала аудита по порядку
+func auditOutcomes(t *testing.T, uc *cleanerUseCase) []string {
+	t.Helper()
+
+	entries, err := uc.audit.List(context.Background(), entities.AuditFilter{Limit: 100})
+	if err != nil {
+		t.Fatalf("list audit entries: %v", err)
+	}
+	outcomes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		outcomes = append(outcomes, e.Outcome)
+	}
+	return outcomes
+}
+
+// countingRepo считает пакеты и запоминает переданные курсоры
+type countingRepo struct {
+	ports.CleanerRepository
+
+	mu      sync.Mutex
+	cursors []entities.BatchCursor
+
+	// afterBatch вызывается после каждого пакета
+	afterBatch func(result entities.BatchResult)
+}
+
+func (r *countingRepo) DeleteBatch(ctx context.Context, tableName string, beforeDate time.Time, batchSize int, cursor entities.BatchCursor, timeouts entities.BatchTimeouts) (entities.BatchResult, error) {
+	result, err := r.CleanerRepository.DeleteBatch(ctx, tableName, beforeDate, batchSize, cursor, timeouts)
+
+	r.mu.Lock()
+	r.cursors = append(r.cursors, cursor)
+	r.mu.Unlock()
+
+	if err == nil && r.afterBatch != nil {
+		r.afterBatch(result)
+	}
+	return result, err
+}
+
+func (r *countingRepo) batches() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cursors)
+}
+
+func TestCleanTableSync(t *testing.T) {
+	db := newTestDB(t, 10, 3)
+	uc := newTestUseCase(db, sqliterepo.NewSQLiteRepository(db, zap.NewNop()))
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		BatchSize:  4,
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+
+	if result.Status != entities.StatusCompleted || result.RowsDeleted != 10 {
+		t.Errorf("result = %s with %d rows deleted, want %s with 10", result.Status, result.RowsDeleted, entities.StatusCompleted)
+	}
+	if result.Verified == nil || result.Verified.RowsRemaining != 0 || result.Verified.Mismatch {
+		t.Errorf("verification = %+v, want no rows remaining", result.Verified)
+	}
+	if n := countEvents(t, db); n != 3 {
+		t.Errorf("%d rows left, want 3 fresh rows", n)
+	}
+	if got := fmt.Sprint(auditOutcomes(t, uc)); got != fmt.Sprint([]string{entities.AuditOutcomeStarted, entities.StatusCompleted}) {
+		t.Errorf("audit outcomes = %s, want started and completed", got)
+	}
+}
+
+func TestCleanTableDryRun(t *testing.T) {
+	db := newTestDB(t, 10, 3)
+	uc := newTestUseCase(db, sqliterepo.NewSQLiteRepository(db, zap.NewNop()))
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+
+	if result.Status != entities.StatusDryRun || result.RowsMatched != 10 || result.RowsDeleted != 0 {
+		t.Errorf("result = %s, matched %d, deleted %d; want %s, 10, 0",
+			result.Status, result.RowsMatched, result.RowsDeleted, entities.StatusDryRun)
+	}
+	if n := countEvents(t, db); n != 13 {
+		t.Errorf("%d rows left, want all 13", n)
+	}
+}
+
+func TestCleanTableLockConflict(t *testing.T) {
+	db := newTestDB(t, 10, 0)
+	repo := sqliterepo.NewSQLiteRepository(db, zap.NewNop())
+	uc := newTestUseCase(db, repo)
+
+	// Таблицу очищает другой процесс
+	acquired, unlock, err := repo.TryAcquireLock(context.Background(), "events")
+	if err != nil || !acquired {
+		t.Fatalf("TryAcquireLock() = %v, %v", acquired, err)
+	}
+	defer unlock()
+
+	_, err = uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+	})
+
+	var conflict entities.LockConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("CleanTable() error = %v, want LockConflictError", err)
+	}
+	if n := countEvents(t, db); n != 10 {
+		t.Errorf("%d rows left, want all 10", n)
+	}
+	if got := fmt.Sprint(auditOutcomes(t, uc)); got != fmt.Sprint([]string{entities.AuditOutcomeStarted, entities.StatusFailed}) {
+		t.Errorf("audit outcomes = %s, want started and failed", got)
+	}
+}
+
+func TestCleanTableCompletedWithLeftovers(t *testing.T) {
+	db := newTestDB(t, 10, 3)
+
+	// Пока шла очистка, в таблицу записали строку старше before_date
+	inserted := false
+	repo := &countingRepo{CleanerRepository: sqliterepo.NewSQLiteRepository(db, zap.NewNop())}
+	repo.afterBatch = func(result entities.BatchResult) {
+		if result.Exhausted && !inserted {
+			inserted = true
+			insertEvent(t, db, testOldDate)
+		}
+	}
+	uc := newTestUseCase(db, repo)
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		BatchSize:  4,
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+
+	if result.Status != entities.StatusCompletedWithLeftovers {
+		t.Errorf("status = %s, want %s", result.Status, entities.StatusCompletedWithLeftovers)
+	}
+	if result.Verified == nil || result.Verified.RowsRemaining != 1 || !result.Verified.Mismatch {
+		t.Errorf("verification = %+v, want one remaining row and a mismatch", result.Verified)
+	}
+	outcomes := auditOutcomes(t, uc)
+	if len(outcomes) != 2 || outcomes[1] != entities.StatusCompletedWithLeftovers {
+		t.Errorf("audit outcomes = %v, want the result recorded as %s", outcomes, entities.StatusCompletedWithLeftovers)
+	}
+}
+
+// SQLite удаляет пакеты без курсора: очистка должна завершиться на первом неполном
+// или пустом пакете, не уходя в повторный проход
+func TestCleanTableWithoutCursorTerminates(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         int
+		wantBatches int
+	}{
+		{"partial last batch", 10, 3},
+		{"exact multiple of the batch size", 8, 3},
+		{"nothing to delete", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, tt.old, 3)
+			repo := &countingRepo{CleanerRepository: sqliterepo.NewSQLiteRepository(db, zap.NewNop())}
+			uc := newTestUseCase(db, repo)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, err := uc.CleanTable(ctx, entities.CleanupRequest{
+				TableName:  "events",
+				BeforeDate: testBeforeDate,
+				BatchSize:  4,
+			})
+			if err != nil {
+				t.Fatalf("CleanTable() error = %v", err)
+			}
+
+			if result.Status != entities.StatusCompleted || result.RowsDeleted != tt.old {
+				t.Errorf("result = %s with %d rows deleted, want %s with %d",
+					result.Status, result.RowsDeleted, entities.StatusCompleted, tt.old)
+			}
+			if got := repo.batches(); got != tt.wantBatches {
+				t.Errorf("%d batches, want %d", got, tt.wantBatches)
+			}
+		})
+	}
+}
+
+// sweepRepo имитирует keyset-репозиторий, который в первом проходе пропустил
+// заблокированную строку: диапазон исчерпан, но строка удаляется только повторным проходом
+type sweepRepo struct {
+	ports.CleanerRepository
+
+	cursors []entities.BatchCursor
+}
+
+func (r *sweepRepo) DeleteBatch(_ context.Context, _ string, _ time.Time, _ int, cursor entities.BatchCursor, _ entities.BatchTimeouts) (entities.BatchResult, error) {
+	r.cursors = append(r.cursors, cursor)
+
+	switch len(r.cursors) {
+	case 1:
+		return entities.BatchResult{Deleted: 4, Next: entities.BatchCursor{CreatedAt: testOldDate, ID: "5"}}, nil
+	case 2:
+		return entities.BatchResult{Deleted: 3, Next: entities.BatchCursor{CreatedAt: testOldDate.Add(time.Hour), ID: "8"}, Exhausted: true}, nil
+	default:
+		return entities.BatchResult{Deleted: 1, Next: entities.BatchCursor{CreatedAt: testOldDate, ID: "1"}, Exhausted: true}, nil
+	}
+}
+
+func TestCleanTableSweepsAfterCursorRange(t *testing.T) {
+	db := newTestDB(t, 0, 0)
+	repo := &sweepRepo{CleanerRepository: sqliterepo.NewSQLiteRepository(db, zap.NewNop())}
+	uc := newTestUseCase(db, repo)
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		BatchSize:  4,
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+
+	// Второй пакет продолжает с курсора первого, третий начинает повторный проход с начала
+	want := []entities.BatchCursor{{}, {CreatedAt: testOldDate, ID: "5"}, {}}
+	if fmt.Sprint(repo.cursors) != fmt.Sprint(want) {
+		t.Errorf("cursors = %v, want %v", repo.cursors, want)
+	}
+	if result.RowsDeleted != 8 {
+		t.Errorf("rows deleted = %d, want 8", result.RowsDeleted)
+	}
+}
+
+func TestCleanTableStopsWhenWindowCloses(t *testing.T) {
+	db := newTestDB(t, 10, 0)
+
+	// Окно закрывается после первого пакета
+	closed, err := entities.ParseMaintenanceWindow(fmt.Sprintf("* %02d:00-%02d:30",
+		(time.Now().UTC().Hour()+12)%24, (time.Now().UTC().Hour()+12)%24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uc *cleanerUseCase
+	repo := &countingRepo{CleanerRepository: sqliterepo.NewSQLiteRepository(db, zap.NewNop())}
+	repo.afterBatch = func(entities.BatchResult) {
+		settings := *uc.currentSettings()
+		settings.MaintenanceWindows = entities.MaintenanceSchedule{Global: []entities.MaintenanceWindow{closed}}
+		uc.ApplySettings(context.Background(), settings)
+	}
+	uc = newTestUseCase(db, repo)
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		BatchSize:  4,
+	})
+
+	var windowErr entities.WindowClosedError
+	if !errors.As(err, &windowErr) {
+		t.Fatalf("CleanTable() error = %v, want WindowClosedError", err)
+	}
+	if windowErr.RowsDeleted != 4 || result == nil || result.RowsDeleted != 4 {
+		t.Errorf("rows deleted = %d (result %+v), want 4", windowErr.RowsDeleted, result)
+	}
+	if windowErr.NextOpen.IsZero() {
+		t.Error("next window opening is not reported")
+	}
+	if n := countEvents(t, db); n != 6 {
+		t.Errorf("%d rows left, want 6", n)
+	}
+}
+
+// partitionedRepo выдает таблицы events_p1 и events_p2 за секции таблицы events
+type partitionedRepo struct {
+	ports.CleanerRepository
+}
+
+func (r *partitionedRepo) ListPartitions(context.Context, string, time.Time) ([]string, error) {
+	return []string{"events_p1", "events_p2"}, nil
+}
+
+func TestCleanTableAuditsRunParameters(t *testing.T) {
+	db := newTestDB(t, 0, 0)
+	for _, partition := range []string{"events_p1", "events_p2"} {
+		db.MustExec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL)", partition))
+		for i := 0; i < 3; i++ {
+			db.MustExec(fmt.Sprintf("INSERT INTO %s (created_at) VALUES (?)", partition), testOldDate)
+		}
+	}
+	uc := newTestUseCase(db, &partitionedRepo{CleanerRepository: sqliterepo.NewSQLiteRepository(db, zap.NewNop())})
+
+	_, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:   "events",
+		BeforeDate:  testBeforeDate,
+		BatchSize:   2,
+		Parallelism: 8,
+		Timeouts:    entities.BatchTimeouts{Lock: entities.Duration(5 * time.Second)},
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+
+	entries, err := uc.audit.List(context.Background(), entities.AuditFilter{Limit: 100})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("audit entries = %d, %v; want 2", len(entries), err)
+	}
+
+	// Параллелизм ограничен настройками, незаданные таймауты взяты из них же
+	settings := uc.currentSettings()
+	wantTimeouts := entities.BatchTimeouts{Lock: entities.Duration(5 * time.Second)}.Or(settings.BatchTimeouts.Default)
+	for _, e := range entries {
+		if e.Parallelism != settings.MaxParallelism || e.BatchTimeouts != wantTimeouts {
+			t.Errorf("%s entry: parallelism %d, timeouts %+v; want %d, %+v",
+				e.Outcome, e.Parallelism, e.BatchTimeouts, settings.MaxParallelism, wantTimeouts)
+		}
+	}
+
+	result := entries[1]
+	if len(result.Partitions) != 2 {
+		t.Fatalf("partitions in the result entry = %+v, want 2", result.Partitions)
+	}
+	for _, p := range result.Partitions {
+		if p.Status != entities.StatusCompleted || p.RowsDeleted != 3 {
+			t.Errorf("partition %s: %s with %d rows, want completed with 3", p.Name, p.Status, p.RowsDeleted)
+		}
+	}
+
+	// Новые поля сохраняются и читаются так, что цепочка хешей сходится
+	verification, err := NewAuditUseCase(uc.audit, zap.NewNop()).Verify(context.Background())
+	if err != nil || !verification.Valid {
+		t.Errorf("Verify() = %+v, %v; want a valid chain", verification, err)
+	}
+}
+
+// recordingMetrics запоминает строки, учтенные в метриках пакетов
+type recordingMetrics struct {
+	noopMetrics
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (m *recordingMetrics) ObserveBatch(_ string, deleted int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, deleted)
+}
+
+func (m *recordingMetrics) rowsDeleted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	total := 0
+	for _, n := range m.batches {
+		total += n
+	}
+	return total
+}
+
+func TestCleanTableTruncateIsMeasuredAndAudited(t *testing.T) {
+	db := newTestDB(t, 10, 0)
+	metrics := &recordingMetrics{}
+	uc := newTestUseCase(db, sqliterepo.NewSQLiteRepository(db, zap.NewNop()), WithMetrics(metrics))
+
+	result, err := uc.CleanTable(context.Background(), entities.CleanupRequest{
+		TableName:     "events",
+		BeforeDate:    testBeforeDate,
+		AllowTruncate: true,
+	})
+	if err != nil {
+		t.Fatalf("CleanTable() error = %v", err)
+	}
+	if result.Strategy != entities.StrategyTruncate || result.RowsDeleted != 10 {
+		t.Fatalf("result = %s with %d rows deleted, want truncate with 10", result.Strategy, result.RowsDeleted)
+	}
+
+	if got := metrics.rowsDeleted(); got != 10 {
+		t.Errorf("rows deleted in metrics = %d, want 10", got)
+	}
+
+	entries, err := uc.audit.List(context.Background(), entities.AuditFilter{Limit: 100})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("audit entries = %d, %v; want 2", len(entries), err)
+	}
+	for _, e := range entries {
+		if !e.AllowTruncate {
+			t.Errorf("%s entry does not record allow_truncate", e.Outcome)
+		}
+	}
+	if entries[0].Method != "" || entries[1].Method != entities.StrategyTruncate {
+		t.Errorf("methods = %q, %q; want none on start and truncate on the result", entries[0].Method, entries[1].Method)
+	}
+}
+
+func TestCleanTableRejectsUnsupportedCopySwap(t *testing.T) {
+	db := newTestDB(t, 10, 0)
+	repo := sqliterepo.NewSQLiteRepository(db, zap.NewNop())
+	uc := newTestUseCase(db, repo)
+
+	req := entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		Strategy:   entities.StrategyCopySwap,
+	}
+	if _, err := uc.CleanTable(context.Background(), req); !errors.Is(err, entities.ErrCopySwapNotSupported) {
+		t.Fatalf("CleanTable() error = %v, want %v", err, entities.ErrCopySwapNotSupported)
+	}
+	if _, err := uc.StartAsyncCleanup(context.Background(), req); !errors.Is(err, entities.ErrCopySwapNotSupported) {
+		t.Fatalf("StartAsyncCleanup() error = %v, want %v", err, entities.ErrCopySwapNotSupported)
+	}
+
+	// Запрос отклонен до захвата блокировки и записи о начале очистки
+	want := []string{entities.AuditOutcomeRejected, entities.AuditOutcomeRejected}
+	if got := auditOutcomes(t, uc); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("audit outcomes = %v, want %v", got, want)
+	}
+	acquired, unlock, err := repo.TryAcquireLock(context.Background(), "events")
+	if err != nil || !acquired {
+		t.Fatalf("table lock is held after the rejection: %v, %v", acquired, err)
+	}
+	unlock()
+	if n := countEvents(t, db); n != 10 {
+		t.Errorf("%d rows left, want all 10", n)
+	}
+}
+
+// fakeCopySwap копирует строки двумя пакетами, а при замене удаляет устаревшие строки events
+type fakeCopySwap struct {
+	db      *sqlx.DB
+	batches int
+}
+
+func (f *fakeCopySwap) PrepareCopySwap(_ context.Context, tableName string, beforeDate time.Time, _ entities.BatchTimeouts) (entities.CopySwap, error) {
+	return entities.CopySwap{Table: tableName, Shadow: "cs_00000001_new", DeltaLog: "cs_00000001_delta", BeforeDate: beforeDate}, nil
+}
+
+func (f *fakeCopySwap) CopyBatch(context.Context, entities.CopySwap, int, entities.BatchCursor, entities.BatchTimeouts) (entities.BatchResult, error) {
+	f.batches++
+	if f.batches == 1 {
+		return entities.BatchResult{Deleted: 2, Next: entities.BatchCursor{CreatedAt: testNewDate, ID: "2"}}, nil
+	}
+	return entities.BatchResult{Deleted: 1, Exhausted: true}, nil
+}
+
+func (f *fakeCopySwap) BuildShadowIndexes(context.Context, entities.CopySwap) error { return nil }
+
+func (f *fakeCopySwap) ApplyDelta(context.Context, entities.CopySwap, int, entities.BatchTimeouts) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeCopySwap) SwapTables(_ context.Context, cs entities.CopySwap, _ entities.BatchTimeouts) error {
+	_, err := f.db.Exec("DELETE FROM events WHERE created_at < ?", cs.BeforeDate)
+	return err
+}
+
+func (f *fakeCopySwap) AbortCopySwap(context.Context, entities.CopySwap) error { return nil }
+
+func TestCopySwapReportsPhasesAndMetrics(t *testing.T) {
+	db := newTestDB(t, 10, 3)
+	metrics := &recordingMetrics{}
+	uc := newTestUseCase(db, sqliterepo.NewSQLiteRepository(db, zap.NewNop()),
+		WithMetrics(metrics), WithCopySwap(&fakeCopySwap{db: db}))
+
+	var (
+		mu     sync.Mutex
+		events []entities.ProgressEvent
+	)
+	task := newTaskState("events", entities.StatusInProgress)
+	task.publish = func(e entities.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+
+	result, err := uc.run(context.Background(), entities.CleanupRequest{
+		TableName:  "events",
+		BeforeDate: testBeforeDate,
+		BatchSize:  2,
+		Strategy:   entities.StrategyCopySwap,
+	}, task)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if result.Status != entities.StatusCompleted || result.RowsDeleted != 10 || result.RowsCopied != 3 {
+		t.Errorf("result = %s, deleted %d, copied %d; want completed, 10, 3",
+			result.Status, result.RowsDeleted, result.RowsCopied)
+	}
+
+	var got []string
+	for _, e := range events {
+		switch e.Type {
+		case entities.EventPhase:
+			got = append(got, e.Phase)
+		case entities.EventBatchCopied:
+			got = append(got, fmt.Sprintf("copied %d", e.BatchRows))
+		case entities.EventBatchCompleted:
+			got = append(got, fmt.Sprintf("deleted %d", e.BatchRows))
+		}
+	}
+	want := []string{
+		entities.PhaseCopy, "copied 2", "copied 1",
+		entities.PhaseBuildIndexes, entities.PhaseDeltaReplay, entities.PhaseSwap,
+		"deleted 10",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	if got := metrics.rowsDeleted(); got != 10 {
+		t.Errorf("rows deleted in metrics = %d, want 10", got)
+	}
+}
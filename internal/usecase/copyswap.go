@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Сколько ждать удаления вспомогательных объектов после прерванной очистки заменой
+const copySwapAbortTimeout = 1 * time.Minute
+
+// copyAndSwap очищает таблицу заменой: сохраняемые строки копируются пакетами в новую таблицу,
+// изменения исходной таблицы за время копирования переносятся из журнала, после чего
+// таблицы меняются местами. Это выгоднее пакетного удаления, когда удаляется большая часть
+// таблицы. При ошибке или отмене вспомогательные объекты удаляются, а исходная таблица
+// остается прежней. При ошибке возвращается статус, которым должна завершиться задача.
+// Поддержка стратегии драйвером проверяется при приеме запроса.
+func (uc *cleanerUseCase) copyAndSwap(ctx context.Context, req entities.CleanupRequest, task *taskState, onBatch func(deleted int) int) (string, error) {
+	var cs entities.CopySwap
+	err := uc.retryTransient(ctx, req, task, func(ctx context.Context, timeouts entities.BatchTimeouts) error {
+		var err error
+		cs, err = uc.copySwap.PrepareCopySwap(ctx, req.TableName, req.BeforeDate, timeouts)
+		return err
+	})
+	if err != nil {
+		return failureStatus(err), fmt.Errorf("prepare copy and swap: %w", err)
+	}
+
+	fields := []zap.Field{zap.String("table", req.TableName), zap.String("shadow_table", cs.Shadow)}
+
+	// Переход к следующему этапу виден в логе, трассировке и событиях задачи
+	enterPhase := func(phase, msg string) {
+		uc.logger.Info(msg, fields...)
+		trace.SpanFromContext(ctx).AddEvent("copy and swap phase", trace.WithAttributes(
+			attribute.String("cleanup.phase", phase)))
+		task.emit(entities.ProgressEvent{Type: entities.EventPhase, Phase: phase})
+	}
+	enterPhase(entities.PhaseCopy, "Copying rows to keep into a new table")
+
+	swapped := false
+	defer func() {
+		if swapped {
+			return
+		}
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), copySwapAbortTimeout)
+		defer cancel()
+		if err := uc.copySwap.AbortCopySwap(abortCtx, cs); err != nil {
+			uc.logger.Error("Failed to remove copy and swap objects", append(fields, zap.Error(err))...)
+		}
+	}()
+
+	// Копирование нагружает базу так же, как удаление, поэтому соблюдает окна обслуживания
+	gate := &sync.Mutex{}
+	var cursor entities.BatchCursor
+	for {
+		if !uc.currentSettings().MaintenanceWindows.IsOpen(req.TableName, time.Now()) {
+			if err := uc.pauseForWindow(ctx, req.TableName, task, gate); err != nil {
+				return entities.StatusCanceled, err
+			}
+		}
+
+		var batch entities.BatchResult
+		err := uc.retryTransient(ctx, req, task, func(ctx context.Context, timeouts entities.BatchTimeouts) error {
+			var err error
+			batch, err = uc.copySwap.CopyBatch(ctx, cs, req.BatchSize, cursor, timeouts)
+			return err
+		})
+		if err != nil {
+			uc.logger.Error("Error copying batch", append(fields, zap.Error(err))...)
+			return failureStatus(err), fmt.Errorf("copy batch: %w", err)
+		}
+
+		var copied int
+		task.update(func(r *entities.CleanupResult) {
+			r.RowsCopied += batch.Deleted
+			copied = r.RowsCopied
+		})
+		uc.logger.Info("Batch copied", append(fields,
+			zap.Int("copied_count", batch.Deleted),
+			zap.Int("total_copied", copied))...)
+		task.emit(entities.ProgressEvent{Type: entities.EventBatchCopied, Phase: entities.PhaseCopy, BatchRows: batch.Deleted})
+
+		if batch.Exhausted {
+			break
+		}
+		cursor = batch.Next
+
+		// Небольшая пауза между пакетами, чтобы снизить нагрузку
+		select {
+		case <-time.After(uc.currentSettings().BatchPause):
+			// Продолжаем выполнение
+		case <-ctx.Done():
+			return entities.StatusCanceled, ctx.Err()
+		}
+	}
+
+	// Индексы строятся после копирования: вставка в таблицу без индексов быстрее
+	enterPhase(entities.PhaseBuildIndexes, "Building indexes of the new table")
+	if err := uc.copySwap.BuildShadowIndexes(ctx, cs); err != nil {
+		return failureStatus(err), fmt.Errorf("build indexes: %w", err)
+	}
+
+	// Журнал догоняется пакетами, чтобы под блокировкой замены осталось немного изменений
+	enterPhase(entities.PhaseDeltaReplay, "Applying changes made during the copy")
+	for {
+		var applied int
+		err := uc.retryTransient(ctx, req, task, func(ctx context.Context, timeouts entities.BatchTimeouts) error {
+			var err error
+			applied, err = uc.copySwap.ApplyDelta(ctx, cs, req.BatchSize, timeouts)
+			return err
+		})
+		if err != nil {
+			return failureStatus(err), fmt.Errorf("apply delta log: %w", err)
+		}
+		uc.logger.Debug("Delta log applied", append(fields, zap.Int("applied_count", applied))...)
+		if applied < req.BatchSize {
+			break
+		}
+	}
+
+	if !uc.currentSettings().MaintenanceWindows.IsOpen(req.TableName, time.Now()) {
+		if err := uc.pauseForWindow(ctx, req.TableName, task, gate); err != nil {
+			return entities.StatusCanceled, err
+		}
+	}
+
+	// Строки, которые исчезнут при замене; считаются до блокировки, чтобы не удерживать ее.
+	// Старые строки, записанные после подсчета, в итог не попадут.
+	deleted, err := uc.repo.CountRows(ctx, req.TableName, req.BeforeDate)
+	if err != nil {
+		return entities.StatusFailed, fmt.Errorf("count rows: %w", err)
+	}
+
+	enterPhase(entities.PhaseSwap, "Swapping tables")
+	swapStart := time.Now()
+	err = uc.retryTransient(ctx, req, task, func(ctx context.Context, timeouts entities.BatchTimeouts) error {
+		return uc.copySwap.SwapTables(ctx, cs, timeouts)
+	})
+	if err != nil {
+		uc.logger.Error("Error swapping tables", append(fields, zap.Error(err))...)
+		return failureStatus(err), fmt.Errorf("swap tables: %w", err)
+	}
+	swapped = true
+
+	uc.logger.Info("Tables swapped", append(fields, zap.Int("deleted_count", deleted))...)
+	trace.SpanFromContext(ctx).AddEvent("tables swapped")
+
+	// Удаленные заменой строки учитываются в метриках как один пакет, как и при пакетном удалении
+	uc.metrics.ObserveBatch(req.TableName, deleted, time.Since(swapStart))
+	onBatch(deleted)
+
+	return entities.StatusCompleted, nil
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"sync"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// Размер буфера канала подписчика; медленный подписчик пропускает промежуточные события
+const subscriberBuffer = 64
+
+// eventHub рассылает события хода выполнения задач подписчикам
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan entities.ProgressEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[string]map[chan entities.ProgressEvent]struct{})}
+}
+
+// subscribe регистрирует подписчика на события задачи
+func (h *eventHub) subscribe(taskID string) (chan entities.ProgressEvent, func()) {
+	ch := make(chan entities.ProgressEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[taskID] == nil {
+		h.subs[taskID] = make(map[chan entities.ProgressEvent]struct{})
+	}
+	h.subs[taskID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[taskID][ch]; ok {
+			delete(h.subs[taskID], ch)
+			close(ch)
+		}
+		if len(h.subs[taskID]) == 0 {
+			delete(h.subs, taskID)
+		}
+	}
+
+	return ch, cancel
+}
+
+// publish отправляет событие всем подписчикам задачи без блокировки.
+// Окончательное событие закрывает каналы подписчиков.
+func (h *eventHub) publish(event entities.ProgressEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[event.TaskID] {
+		select {
+		case ch <- event:
+		default:
+			// Подписчик не успевает читать, промежуточное событие пропускается
+		}
+
+		if event.Type == entities.EventFinished {
+			close(ch)
+		}
+	}
+
+	if event.Type == entities.EventFinished {
+		delete(h.subs, event.TaskID)
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"testing"
+
+	"data-cleaner/internal/models/entities"
+)
+
+func TestEventHub(t *testing.T) {
+	tests := []struct {
+		name       string
+		publish    []entities.ProgressEvent
+		wantTypes  []string
+		wantClosed bool
+	}{
+		{
+			name: "events arrive in publish order",
+			publish: []entities.ProgressEvent{
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 10},
+				{Type: entities.EventPaused, TaskID: "t-1"},
+				{Type: entities.EventResumed, TaskID: "t-1"},
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 20},
+			},
+			wantTypes: []string{entities.EventBatchCompleted, entities.EventPaused, entities.EventResumed, entities.EventBatchCompleted},
+		},
+		{
+			name: "finished event closes the channel",
+			publish: []entities.ProgressEvent{
+				{Type: entities.EventBatchCompleted, TaskID: "t-1", RowsDeleted: 10},
+				{Type: entities.EventFinished, TaskID: "t-1", Status: entities.StatusCompleted},
+			},
+			wantTypes:  []string{entities.EventBatchCompleted, entities.EventFinished},
+			wantClosed: true,
+		},
+		{
+			name: "events of other tasks are not delivered",
+			publish: []entities.ProgressEvent{
+				{Type: entities.EventBatchCompleted, TaskID: "t-2"},
+				{Type: entities.EventFinished, TaskID: "t-2"},
+				{Type: entities.EventBatchCompleted, TaskID: "t-1"},
+			},
+			wantTypes: []string{entities.EventBatchCompleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := newEventHub()
+			events, cancel := hub.subscribe("t-1")
+			defer cancel()
+
+			for _, event := range tt.publish {
+				hub.publish(event)
+			}
+
+			var got []string
+			closed := false
+		read:
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						closed = true
+						break read
+					}
+					got = append(got, event.Type)
+				default:
+					break read
+				}
+			}
+
+			if len(got) != len(tt.wantTypes) {
+				t.Fatalf("events = %v, want %v", got, tt.wantTypes)
+			}
+			for i := range got {
+				if got[i] != tt.wantTypes[i] {
+					t.Fatalf("events = %v, want %v", got, tt.wantTypes)
+				}
+			}
+			if closed != tt.wantClosed {
+				t.Errorf("channel closed = %v, want %v", closed, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func TestEventHubClosesSlowSubscriberOnFinish(t *testing.T) {
+	hub := newEventHub()
+	events, cancel := hub.subscribe("t-1")
+
+	// Переполненный буфер не блокирует публикацию, а канал закрывается после завершения
+	for i := 0; i < subscriberBuffer*2; i++ {
+		hub.publish(entities.ProgressEvent{Type: entities.EventBatchCompleted, TaskID: "t-1"})
+	}
+	hub.publish(entities.ProgressEvent{Type: entities.EventFinished, TaskID: "t-1"})
+
+	n := 0
+	for range events {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events, want the %d that fit in the buffer", n, subscriberBuffer)
+	}
+
+	// Отписка после завершения не закрывает канал повторно
+	cancel()
+	if len(hub.subs) != 0 {
+		t.Errorf("subscribers left after finish: %v", hub.subs)
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+	"data-cleaner/internal/pkg/version"
+
+	"go.uber.org/zap"
+)
+
+// Доля занятых соединений, при которой пул считается насыщенным
+const poolSaturationThreshold = 0.9
+
+type healthUseCase struct {
+	repo        ports.HealthRepository
+	cleaner     ports.CleanerUseCase
+	logger      *zap.Logger
+	pingTimeout time.Duration
+}
+
+// NewHealthUseCase создает сервис проверок работоспособности
+func NewHealthUseCase(repo ports.HealthRepository, cleaner ports.CleanerUseCase, logger *zap.Logger, pingTimeout time.Duration) ports.HealthUseCase {
+	return &healthUseCase{
+		repo:        repo,
+		cleaner:     cleaner,
+		logger:      logger,
+		pingTimeout: pingTimeout,
+	}
+}
+
+// Liveness проверяет, что процесс жив; зависимости не проверяются,
+// чтобы недоступность базы не приводила к перезапуску контейнера
+func (uc *healthUseCase) Liveness(ctx context.Context) *entities.HealthReport {
+	build := version.Get()
+	return &entities.HealthReport{
+		Status: entities.HealthStatusOK,
+		Time:   time.Now(),
+		Build:  &build,
+	}
+}
+
+// Readiness проверяет доступность базы данных и состояние пула соединений
+func (uc *healthUseCase) Readiness(ctx context.Context) *entities.HealthReport {
+	build := version.Get()
+	pool := uc.repo.PoolStats()
+	tasks := uc.cleaner.GetTaskStats(ctx)
+
+	report := &entities.HealthReport{
+		Status: entities.HealthStatusOK,
+		Time:   time.Now(),
+		Checks: make(map[string]entities.HealthCheck),
+		Pool:   &pool,
+		Tasks:  &tasks,
+		Build:  &build,
+	}
+
+	// Проверяем доступность базы данных с таймаутом
+	pingCtx, cancel := context.WithTimeout(ctx, uc.pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	dbCheck := entities.HealthCheck{Status: entities.HealthStatusOK}
+	if err := uc.repo.Ping(pingCtx); err != nil {
+		uc.logger.Warn("Database readiness check failed", zap.Error(err))
+		dbCheck.Status = entities.HealthStatusUnhealthy
+		dbCheck.Error = err.Error()
+		report.Status = entities.HealthStatusUnhealthy
+	}
+	dbCheck.Duration = time.Since(start)
+	report.Checks["database"] = dbCheck
+
+	// Насыщение пула не делает сервис неготовым, но отражается в отчете
+	poolCheck := entities.HealthCheck{Status: entities.HealthStatusOK}
+	if pool.Saturation >= poolSaturationThreshold {
+		poolCheck.Status = entities.HealthStatusDegraded
+		if report.Status == entities.HealthStatusOK {
+			report.Status = entities.HealthStatusDegraded
+		}
+	}
+	report.Checks["connection_pool"] = poolCheck
+
+	return report
+}
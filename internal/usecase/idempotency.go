@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// idempotencyRecord связывает ключ идемпотентности с созданной по нему задачей
+type idempotencyRecord struct {
+	fingerprint string
+	expiresAt   time.Time
+	taskID      string
+	// ready закрывается, когда задача создана или запрос отклонен
+	ready chan struct{}
+}
+
+// idempotencyStore хранит ключи идемпотентности асинхронных задач
+type idempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotencyRecord
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{records: make(map[string]*idempotencyRecord)}
+}
+
+// reserve возвращает запись, созданную ранее по ключу (existing = true), или резервирует ключ
+// за текущим запросом. Параллельный запрос с тем же ключом дожидается, пока первый создаст
+// задачу или будет отклонен.
+func (s *idempotencyStore) reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (rec *idempotencyRecord, existing bool, err error) {
+	for {
+		s.mu.Lock()
+		rec := s.records[key]
+		if rec == nil {
+			rec = &idempotencyRecord{
+				fingerprint: fingerprint,
+				expiresAt:   time.Now().Add(ttl),
+				ready:       make(chan struct{}),
+			}
+			s.records[key] = rec
+			s.mu.Unlock()
+			return rec, false, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-rec.ready:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+
+		// Отклоненный запрос освобождает ключ, и резервирование повторяется
+		if rec.taskID != "" {
+			return rec, true, nil
+		}
+	}
+}
+
+// complete привязывает зарезервированный ключ к задаче и планирует его удаление
+func (s *idempotencyStore) complete(key string, rec *idempotencyRecord, taskID string) {
+	rec.taskID = taskID
+	close(rec.ready)
+
+	time.AfterFunc(time.Until(rec.expiresAt), func() {
+		s.mu.Lock()
+		if s.records[key] == rec {
+			delete(s.records, key)
+		}
+		s.mu.Unlock()
+	})
+}
+
+// release освобождает ключ, если задача по нему не была создана
+func (s *idempotencyStore) release(key string, rec *idempotencyRecord) {
+	s.mu.Lock()
+	if s.records[key] == rec {
+		delete(s.records, key)
+	}
+	s.mu.Unlock()
+	close(rec.ready)
+}
+
+// idempotencyScope возвращает ключ, уникальный в пределах клиента,
+// чтобы разные клиенты не видели задачи друг друга
+func idempotencyScope(ctx context.Context, key string) string {
+	actor := entities.AnonymousActor
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		actor = principal.ID
+	}
+	return actor + "\x00" + key
+}
@@ -0,0 +1,152 @@
+//go:build cgo
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+	sqliterepo "data-cleaner/internal/repository/sqlite"
+
+	"go.uber.org/zap"
+)
+
+// waitForTask ждет завершения асинхронной задачи, чтобы она не пережила тестовую базу
+func waitForTask(t *testing.T, uc *cleanerUseCase, taskID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := uc.GetCleanupStatus(context.Background(), taskID)
+		if err != nil {
+			t.Fatalf("get status of %s: %v", taskID, err)
+		}
+		if entities.IsTerminalStatus(status.Status) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("task %s did not finish", taskID)
+}
+
+func TestStartAsyncCleanupIdempotency(t *testing.T) {
+	valid := entities.CleanupRequest{TableName: "events", BeforeDate: testBeforeDate, BatchSize: 10}
+	other := valid
+	other.BatchSize = 20
+	invalid := valid
+	invalid.TableName = ""
+
+	// submit - отправка запроса с ключом от имени клиента
+	type submit struct {
+		actor   string
+		req     entities.CleanupRequest
+		wantErr error
+		// sameAs - номер предыдущей отправки, задачу которой должен вернуть повтор; -1 - новая задача
+		sameAs int
+	}
+
+	tests := []struct {
+		name    string
+		submits []submit
+	}{
+		{
+			name: "repeated request returns the original task",
+			submits: []submit{
+				{actor: "ops", req: valid, sameAs: -1},
+				{actor: "ops", req: valid, sameAs: 0},
+			},
+		},
+		{
+			name: "different request with the same key conflicts",
+			submits: []submit{
+				{actor: "ops", req: valid, sameAs: -1},
+				{actor: "ops", req: other, wantErr: entities.IdempotencyConflictError{Key: "key-1"}},
+			},
+		},
+		{
+			name: "keys of different clients do not collide",
+			submits: []submit{
+				{actor: "ops", req: valid, sameAs: -1},
+				{actor: "billing", req: other, sameAs: -1},
+			},
+		},
+		{
+			name: "rejected request releases the key",
+			submits: []submit{
+				{actor: "ops", req: invalid, wantErr: entities.ErrEmptyTableName},
+				{actor: "ops", req: valid, sameAs: -1},
+				{actor: "ops", req: valid, sameAs: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, 5, 5)
+			uc := newTestUseCase(db, sqliterepo.NewSQLiteRepository(db, zap.NewNop()))
+
+			taskIDs := make([]string, len(tt.submits))
+			for i, s := range tt.submits {
+				ctx := entities.WithPrincipal(context.Background(), &entities.Principal{
+					ID:    s.actor,
+					Scope: entities.AuthScope{Tables: []string{"*.*"}, Modes: []string{entities.ModeAsync}},
+				})
+				ctx = entities.WithIdempotencyKey(ctx, "key-1")
+				taskID, err := uc.StartAsyncCleanup(ctx, s.req)
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("submit %d: error = %v, want %v", i, err, s.wantErr)
+				}
+				if err != nil {
+					continue
+				}
+				taskIDs[i] = taskID
+				waitForTask(t, uc, taskID)
+
+				if s.sameAs >= 0 && taskID != taskIDs[s.sameAs] {
+					t.Errorf("submit %d: task = %s, want the task of submit %d (%s)", i, taskID, s.sameAs, taskIDs[s.sameAs])
+				}
+				for j := 0; s.sameAs < 0 && j < i; j++ {
+					if taskID == taskIDs[j] {
+						t.Errorf("submit %d: task = %s, want a new task", i, taskID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreWaitsForReservation(t *testing.T) {
+	store := newIdempotencyStore()
+	rec, existing, err := store.reserve(context.Background(), "k", "fp", time.Hour)
+	if err != nil || existing {
+		t.Fatalf("reserve() = %v, %v, want a new reservation", existing, err)
+	}
+
+	// Параллельный запрос с тем же ключом ждет, пока первый создаст задачу
+	done := make(chan string)
+	go func() {
+		rec, _, _ := store.reserve(context.Background(), "k", "fp", time.Hour)
+		done <- rec.taskID
+	}()
+	select {
+	case <-done:
+		t.Fatal("second reservation did not wait for the first one")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	store.complete("k", rec, "task-1")
+	if got := <-done; got != "task-1" {
+		t.Errorf("second reservation got task %q, want task-1", got)
+	}
+
+	// Ожидание прерывается отменой контекста
+	store.reserve(context.Background(), "other", "fp", time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := store.reserve(ctx, "other", "fp", time.Hour); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("reserve() error = %v, want deadline exceeded", err)
+	}
+}
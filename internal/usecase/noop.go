@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// noopMetrics используется, когда сбор метрик не настроен
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveBatch(string, int, time.Duration) {}
+func (noopMetrics) IncLockFailures(string)                  {}
+func (noopMetrics) IncBatchRetries(string)                  {}
+func (noopMetrics) AddRunningTasks(int)                     {}
+func (noopMetrics) AddQueuedTasks(int)                      {}
+
+// noopNotifier используется, когда уведомления не настроены
+type noopNotifier struct{}
+
+func (noopNotifier) Notify(context.Context, entities.WebhookEvent, string) {}
+func (noopNotifier) CheckCallbackURL(string) error                         { return entities.ErrCallbacksDisabled }
+
+// noopAudit используется, когда журнал аудита не настроен
+type noopAudit struct{}
+
+func (noopAudit) Append(context.Context, *entities.AuditEntry) error { return nil }
+func (noopAudit) List(context.Context, entities.AuditFilter) ([]entities.AuditEntry, error) {
+	return nil, nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"data-cleaner/internal/models/entities"
+	"data-cleaner/internal/models/ports"
+)
+
+// Option настраивает сервис очистки данных
+type Option func(*cleanerUseCase)
+
+// WithMaintenanceSchedule задает окна обслуживания, вне которых удаление не выполняется
+func WithMaintenanceSchedule(schedule entities.MaintenanceSchedule) Option {
+	return func(uc *cleanerUseCase) {
+		uc.updateSettings(func(s *entities.RuntimeSettings) {
+			s.MaintenanceWindows = schedule
+		})
+	}
+}
+
+// WithSettings задает настройки очистки, которые затем можно менять через ApplySettings
+func WithSettings(settings entities.RuntimeSettings) Option {
+	return func(uc *cleanerUseCase) {
+		uc.settings.Store(&settings)
+	}
+}
+
+// WithMetrics задает получателя метрик операций очистки
+func WithMetrics(metrics ports.CleanerMetrics) Option {
+	return func(uc *cleanerUseCase) {
+		uc.metrics = metrics
+	}
+}
+
+// WithTablePolicy задает списки разрешенных и защищенных таблиц
+func WithTablePolicy(policy entities.TablePolicy) Option {
+	return func(uc *cleanerUseCase) {
+		uc.updateSettings(func(s *entities.RuntimeSettings) {
+			s.TablePolicy = policy
+		})
+	}
+}
+
+// WithAuditLog задает хранилище журнала аудита операций очистки
+func WithAuditLog(audit ports.AuditRepository) Option {
+	return func(uc *cleanerUseCase) {
+		uc.audit = audit
+	}
+}
+
+// WithNotifier задает отправителя уведомлений о завершении асинхронных задач
+func WithNotifier(notifier ports.Notifier) Option {
+	return func(uc *cleanerUseCase) {
+		uc.notifier = notifier
+	}
+}
+
+// WithCopySwap задает репозиторий очистки заменой таблицы; без него стратегия copy_swap недоступна
+func WithCopySwap(repo ports.CopySwapRepository) Option {
+	return func(uc *cleanerUseCase) {
+		uc.copySwap = repo
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// eligiblePartitions возвращает секции для очистки по отдельности или nil, если таблица
+// очищается через родительскую таблицу. По секциям очищается таблица, для которой задан
+// параллелизм или разрешен TRUNCATE: устаревшие секции можно очистить целиком.
+func (uc *cleanerUseCase) eligiblePartitions(ctx context.Context, req entities.CleanupRequest) []string {
+	if !req.AllowTruncate && min(req.Parallelism, uc.currentSettings().MaxParallelism) <= 1 {
+		return nil
+	}
+
+	partitions, err := uc.repo.ListPartitions(ctx, req.TableName, req.BeforeDate)
+	if err != nil {
+		uc.logger.Warn("Failed to list partitions, cleaning serially",
+			zap.String("table", req.TableName),
+			zap.Error(err))
+		return nil
+	}
+	return partitions
+}
+
+// deletePartitions очищает секции параллельно ограниченным числом исполнителей
+// (одним, если параллелизм не задан).
+// Пакеты каждого исполнителя выполняются в отдельных транзакциях на своих соединениях пула.
+// Ошибка в одной секции отменяет остальные; ход выполнения суммируется в задаче,
+// а итог каждой секции сохраняется в результате.
+func (uc *cleanerUseCase) deletePartitions(ctx context.Context, req entities.CleanupRequest, partitions []string, task *taskState, onBatch func(deleted int) int) (string, error) {
+	workers := max(1, min(req.Parallelism, uc.currentSettings().MaxParallelism, len(partitions)))
+	uc.logger.Info("Cleaning partitions",
+		zap.String("table", req.TableName),
+		zap.Int("partitions", len(partitions)),
+		zap.Int("workers", workers))
+
+	task.update(func(r *entities.CleanupResult) {
+		r.Partitions = make([]entities.PartitionResult, len(partitions))
+		for i, name := range partitions {
+			r.Partitions[i] = entities.PartitionResult{Name: name, Status: entities.StatusPending}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		firstErr    error
+		firstStatus string
+		gate        sync.Mutex
+		wg          sync.WaitGroup
+	)
+
+	jobs := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				status, err := uc.deletePartition(ctx, req, i, partitions[i], task, onBatch, &gate)
+				if err == nil {
+					continue
+				}
+
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("partition %s: %w", partitions[i], err)
+					firstStatus = status
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := range partitions {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Секции, до которых не дошла очередь, считаются отмененными
+	task.update(func(r *entities.CleanupResult) {
+		for i := range r.Partitions {
+			if r.Partitions[i].Status == entities.StatusPending {
+				r.Partitions[i].Status = entities.StatusCanceled
+			}
+		}
+		r.Strategy = partitionStrategy(r.Partitions)
+	})
+
+	if firstErr != nil {
+		return firstStatus, firstErr
+	}
+	return entities.StatusCompleted, nil
+}
+
+// deletePartition очищает одну секцию и отражает ее итог в результате задачи
+func (uc *cleanerUseCase) deletePartition(ctx context.Context, req entities.CleanupRequest, index int, name string, task *taskState, onBatch func(deleted int) int, gate *sync.Mutex) (string, error) {
+	ctx, span := tracer.Start(ctx, "cleanerUseCase.deletePartition", trace.WithAttributes(
+		attribute.String("db.sql.table", req.TableName),
+		attribute.String("cleanup.partition", name),
+	))
+	defer span.End()
+
+	updatePartition := func(fn func(p *entities.PartitionResult)) {
+		task.update(func(r *entities.CleanupResult) {
+			fn(&r.Partitions[index])
+		})
+	}
+
+	start := time.Now()
+	updatePartition(func(p *entities.PartitionResult) {
+		p.Status = entities.StatusInProgress
+		p.Strategy = entities.StrategyBatchDelete
+	})
+
+	onPartitionBatch := func(n int) int {
+		updatePartition(func(p *entities.PartitionResult) {
+			p.RowsDeleted += n
+			p.ElapsedTime = time.Since(start)
+		})
+		return onBatch(n)
+	}
+
+	deleted, status, err := uc.truncatePartition(ctx, req, name, task, updatePartition, onPartitionBatch, gate)
+	if err == nil && status == "" {
+		deleted, status, err = uc.deleteRange(ctx, req, name, task, onPartitionBatch, gate)
+	}
+
+	updatePartition(func(p *entities.PartitionResult) {
+		p.Status = status
+		p.RowsDeleted = deleted
+		p.ElapsedTime = time.Since(start)
+		if err != nil && status != entities.StatusCanceled {
+			p.ErrorMessage = err.Error()
+		}
+	})
+
+	span.SetAttributes(
+		attribute.Int("cleanup.rows_deleted", deleted),
+		attribute.String("cleanup.status", status))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return status, err
+}
+
+// truncatePartition очищает секцию командой TRUNCATE, если запрос это разрешает и все ее
+// записи устарели. Пустой статус без ошибки означает, что секцию нужно очистить пакетами.
+func (uc *cleanerUseCase) truncatePartition(ctx context.Context, req entities.CleanupRequest, name string, task *taskState,
+	updatePartition func(fn func(p *entities.PartitionResult)), onBatch func(deleted int) int, gate *sync.Mutex) (int, string, error) {
+	if !req.AllowTruncate {
+		return 0, "", nil
+	}
+
+	// TRUNCATE тоже изменяет данные, поэтому ждет окна обслуживания, как и пакеты
+	if !uc.currentSettings().MaintenanceWindows.IsOpen(req.TableName, time.Now()) {
+		if err := uc.pauseForWindow(ctx, req.TableName, task, gate); err != nil {
+			return 0, entities.StatusCanceled, err
+		}
+	}
+
+	truncation, err := uc.truncate(ctx, req, name)
+	if err != nil {
+		return 0, entities.StatusCanceled, err
+	}
+	if !truncation.Truncated {
+		updatePartition(func(p *entities.PartitionResult) {
+			p.StrategyReason = truncation.Reason
+		})
+		return 0, "", nil
+	}
+
+	updatePartition(func(p *entities.PartitionResult) {
+		p.Strategy = entities.StrategyTruncate
+	})
+	onBatch(truncation.Rows)
+	return truncation.Rows, entities.StatusCompleted, nil
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// checkTablePolicy проверяет таблицу по спискам разрешенных и защищенных таблиц
+func (uc *cleanerUseCase) checkTablePolicy(ctx context.Context, req entities.CleanupRequest, mode string) error {
+	err := uc.currentSettings().TablePolicy.Check(req.TableName)
+	if err == nil {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.String("table", req.TableName),
+		zap.String("mode", mode),
+		zap.Time("before_date", req.BeforeDate),
+		zap.Error(err),
+	}
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		fields = append(fields, zap.String("principal", principal.ID))
+	}
+	uc.logger.Warn("Cleanup rejected by table policy", fields...)
+
+	return err
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// deleteBatch удаляет пакет данных из таблицы или ее секции target, повторяя его после
+// временных ошибок базы данных
+func (uc *cleanerUseCase) deleteBatch(ctx context.Context, req entities.CleanupRequest, target string, cursor entities.BatchCursor, task *taskState) (entities.BatchResult, time.Duration, error) {
+	var (
+		batch         entities.BatchResult
+		batchDuration time.Duration
+	)
+	err := uc.retryTransient(ctx, req, task, func(ctx context.Context, timeouts entities.BatchTimeouts) error {
+		batchStart := time.Now()
+		var err error
+		batch, err = uc.repo.DeleteBatch(ctx, target, req.BeforeDate, req.BatchSize, cursor, timeouts)
+		batchDuration = time.Since(batchStart)
+		return err
+	})
+	return batch, batchDuration, err
+}
+
+// retryTransient выполняет операцию с таймаутами пакета. После временной ошибки базы данных
+// операция повторяется с экспоненциальной задержкой, пока не исчерпан бюджет повторов;
+// число повторов и последняя временная ошибка сохраняются в состоянии задачи.
+func (uc *cleanerUseCase) retryTransient(ctx context.Context, req entities.CleanupRequest, task *taskState, op func(ctx context.Context, timeouts entities.BatchTimeouts) error) error {
+	for attempt := 1; ; attempt++ {
+		// Таймауты запроса дополняются настройками таблицы; читаются на каждой попытке,
+		// чтобы подхватывать перезагруженные настройки
+		timeouts := req.Timeouts.Or(uc.currentSettings().BatchTimeouts.For(req.TableName))
+
+		iterCtx, cancel := context.WithTimeout(ctx, clientBatchTimeout(timeouts))
+		err := op(iterCtx, timeouts)
+		if err != nil && iterCtx.Err() != nil && ctx.Err() == nil {
+			// Истек клиентский таймаут пакета, а не контекст всей операции
+			var timeoutErr entities.TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				err = entities.TimeoutError{Kind: entities.TimeoutClient, Err: err}
+			}
+		}
+		cancel()
+		if err == nil || !entities.IsTransient(err) {
+			return err
+		}
+
+		// Бюджет читается на каждой попытке, чтобы подхватывать перезагруженные настройки
+		policy := uc.currentSettings().Retry
+		if attempt > policy.MaxRetries {
+			return fmt.Errorf("giving up after %d retries: %w", policy.MaxRetries, err)
+		}
+
+		delay := policy.Backoff(attempt)
+		uc.logger.Warn("Transient database error, retrying",
+			zap.String("table", req.TableName),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		uc.metrics.IncBatchRetries(req.TableName)
+		trace.SpanFromContext(ctx).AddEvent("batch retry", trace.WithAttributes(
+			attribute.Int("retry.attempt", attempt),
+			attribute.String("retry.error", err.Error())))
+
+		task.update(func(r *entities.CleanupResult) {
+			r.Retries++
+			r.LastTransientError = err.Error()
+		})
+		task.emit(entities.ProgressEvent{Type: entities.EventRetrying, Reason: err.Error()})
+
+		select {
+		case <-time.After(delay):
+			// Повторяем операцию
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// failureStatus возвращает статус задачи, завершившейся ошибкой.
+// Таймауты отделяются от прочих ошибок, чтобы их можно было обработать отдельно.
+func failureStatus(err error) string {
+	var timeoutErr entities.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return entities.StatusTimedOut
+	}
+	return entities.StatusFailed
+}
+
+const (
+	// defaultClientBatchTimeout ограничивает пакет, если statement_timeout не задан
+	defaultClientBatchTimeout = 30 * time.Second
+	// clientBatchTimeoutGrace дает серверу время прервать запрос самому
+	// и вернуть ошибку таймаута раньше, чем сработает клиентский
+	clientBatchTimeoutGrace = 5 * time.Second
+)
+
+// clientBatchTimeout возвращает клиентский таймаут пакета. Он страхует от зависшего
+// соединения и срабатывает позже серверного statement_timeout.
+func clientBatchTimeout(timeouts entities.BatchTimeouts) time.Duration {
+	if timeouts.Statement > 0 {
+		return time.Duration(timeouts.Statement) + clientBatchTimeoutGrace
+	}
+	return defaultClientBatchTimeout
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+func TestClientBatchTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts entities.BatchTimeouts
+		want     time.Duration
+	}{
+		{"no statement timeout", entities.BatchTimeouts{}, defaultClientBatchTimeout},
+		{"lock timeout only", entities.BatchTimeouts{Lock: entities.Duration(time.Second)}, defaultClientBatchTimeout},
+		// Сервер прерывает запрос сам и успевает вернуть ошибку таймаута
+		{"statement timeout", entities.BatchTimeouts{Statement: entities.Duration(2 * time.Minute)}, 2*time.Minute + clientBatchTimeoutGrace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientBatchTimeout(tt.timeouts); got != tt.want {
+				t.Errorf("clientBatchTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
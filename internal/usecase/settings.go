@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// ApplySettings заменяет настройки очистки без перезапуска.
+// Выполняющиеся задачи подхватывают новые значения на границе следующего пакета.
+func (uc *cleanerUseCase) ApplySettings(_ context.Context, settings entities.RuntimeSettings) {
+	uc.settings.Store(&settings)
+
+	uc.logger.Info("Cleanup settings applied",
+		zap.Int("default_batch_size", settings.DefaultBatchSize),
+		zap.Duration("max_request_time", settings.MaxRequestTime),
+		zap.Duration("batch_pause", settings.BatchPause),
+		zap.Duration("idempotency_key_ttl", settings.IdempotencyKeyTTL),
+		zap.Int("max_batch_retries", settings.Retry.MaxRetries),
+		zap.Int("max_parallelism", settings.MaxParallelism),
+		zap.Duration("batch_statement_timeout", time.Duration(settings.BatchTimeouts.Default.Statement)),
+		zap.Duration("batch_lock_timeout", time.Duration(settings.BatchTimeouts.Default.Lock)),
+		zap.Strings("table_allowlist", settings.TablePolicy.Allow),
+		zap.Strings("table_denylist", settings.TablePolicy.Deny))
+}
+
+// currentSettings возвращает действующие настройки очистки
+func (uc *cleanerUseCase) currentSettings() *entities.RuntimeSettings {
+	return uc.settings.Load()
+}
+
+// updateSettings изменяет копию текущих настроек и атомарно подменяет их
+func (uc *cleanerUseCase) updateSettings(fn func(s *entities.RuntimeSettings)) {
+	settings := *uc.settings.Load()
+	fn(&settings)
+	uc.settings.Store(&settings)
+}
+
+// withDefaults подставляет значения по умолчанию в незаполненные поля запроса
+func withDefaults(req entities.CleanupRequest, settings *entities.RuntimeSettings) entities.CleanupRequest {
+	if req.BatchSize == 0 {
+		req.BatchSize = settings.DefaultBatchSize
+	}
+	return req
+}
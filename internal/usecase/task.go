@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+)
+
+// taskState хранит изменяемое состояние операции очистки
+type taskState struct {
+	mu      sync.RWMutex
+	result  entities.CleanupResult
+	id      string
+	publish func(entities.ProgressEvent)
+	// Клиент, запустивший задачу; пусто, если аутентификация отключена
+	owner string
+	// Срок действия ключа идемпотентности, по которому создана задача
+	retainUntil time.Time
+	// Синхронная задача: клиент ждет ответа, поэтому закрытие окна обслуживания
+	// останавливает ее, а не приостанавливает
+	sync bool
+}
+
+func newTaskState(tableName, status string) *taskState {
+	return &taskState{
+		result: entities.CleanupResult{
+			TableName: tableName,
+			Status:    status,
+		},
+	}
+}
+
+// update изменяет состояние задачи под блокировкой
+func (t *taskState) update(fn func(r *entities.CleanupResult)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.result)
+}
+
+// setStatus обновляет статус задачи
+func (t *taskState) setStatus(status string) {
+	t.update(func(r *entities.CleanupResult) {
+		r.Status = status
+	})
+}
+
+// snapshot возвращает копию текущего состояния задачи
+func (t *taskState) snapshot() *entities.CleanupResult {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	resultCopy := t.result
+	resultCopy.Partitions = slices.Clone(t.result.Partitions)
+	return &resultCopy
+}
+
+// emit публикует событие хода выполнения, дополняя его текущим состоянием задачи.
+// Для синхронных операций подписчиков нет, и событие отбрасывается.
+func (t *taskState) emit(event entities.ProgressEvent) {
+	if t.publish == nil {
+		return
+	}
+
+	snapshot := t.snapshot()
+	event.TaskID = t.id
+	event.Time = time.Now().UTC()
+	event.Status = snapshot.Status
+	event.RowsDeleted = snapshot.RowsDeleted
+	event.Percent = snapshot.PercentComplete
+	event.ETA = snapshot.ETA
+	t.publish(event)
+}
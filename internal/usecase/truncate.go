@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// truncate очищает таблицу или секцию target командой TRUNCATE, если все ее записи
+// старше before_date. Ошибка проверки не прерывает очистку: она становится причиной,
+// по которой target очищается пакетами. Возвращается только ошибка отмены контекста.
+// Клиентский таймаут не задается: проверка выполняется несколькими запросами,
+// каждый из которых ограничен серверными таймаутами пакета.
+func (uc *cleanerUseCase) truncate(ctx context.Context, req entities.CleanupRequest, target string) (entities.TruncateResult, error) {
+	fields := []zap.Field{zap.String("table", req.TableName)}
+	if target != req.TableName {
+		fields = append(fields, zap.String("partition", target))
+	}
+
+	timeouts := req.Timeouts.Or(uc.currentSettings().BatchTimeouts.For(req.TableName))
+	start := time.Now()
+	result, err := uc.repo.TruncateIfExpired(ctx, target, req.BeforeDate, timeouts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		uc.logger.Warn("Truncate check failed, deleting in batches", append(fields, zap.Error(err))...)
+		result = entities.TruncateResult{Reason: fmt.Sprintf("truncate check failed: %v", err)}
+	}
+
+	if result.Truncated {
+		// Строки, удаленные TRUNCATE, учитываются в метриках как один пакет
+		uc.metrics.ObserveBatch(req.TableName, result.Rows, time.Since(start))
+		uc.logger.Info("Table truncated", append(fields, zap.Int("deleted_count", result.Rows))...)
+		trace.SpanFromContext(ctx).AddEvent("table truncated", trace.WithAttributes(
+			attribute.String("cleanup.target", target),
+			attribute.Int("cleanup.rows_deleted", result.Rows)))
+	} else {
+		uc.logger.Info("Truncate not applicable, deleting in batches", append(fields, zap.String("reason", result.Reason))...)
+	}
+
+	return result, nil
+}
+
+// partitionStrategy сводит способы очистки секций в способ очистки таблицы
+func partitionStrategy(partitions []entities.PartitionResult) string {
+	strategy := ""
+	for _, p := range partitions {
+		switch {
+		case p.Strategy == "":
+			// Секция не очищалась
+		case strategy == "":
+			strategy = p.Strategy
+		case strategy != p.Strategy:
+			return entities.StrategyMixed
+		}
+	}
+
+	if strategy == "" {
+		return entities.StrategyBatchDelete
+	}
+	return strategy
+}
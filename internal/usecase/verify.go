@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// verify подсчитывает записи старше before_date, оставшиеся после очистки, и сверяет их
+// с числом удаленных строк. Расхождением считаются оставшиеся записи, а при точной оценке
+// объема - и сумма удаленных и оставшихся, отличная от оценки. Ошибка подсчета не меняет
+// исход очистки: она сохраняется в отчете проверки.
+func (uc *cleanerUseCase) verify(ctx context.Context, req entities.CleanupRequest, deleted, estimated int, exact bool) entities.Verification {
+	fields := []zap.Field{zap.String("table", req.TableName)}
+
+	v := entities.Verification{
+		RowsDeleted:   deleted,
+		RowsEstimated: estimated,
+	}
+	remaining, err := uc.repo.CountRows(ctx, req.TableName, req.BeforeDate)
+	v.CheckedAt = time.Now().UTC()
+	if err != nil {
+		uc.logger.Warn("Failed to verify cleanup", append(fields, zap.Error(err))...)
+		v.Error = err.Error()
+		return v
+	}
+
+	v.RowsRemaining = remaining
+	v.Mismatch = remaining > 0 || (exact && deleted+remaining != estimated)
+
+	trace.SpanFromContext(ctx).AddEvent("cleanup verified", trace.WithAttributes(
+		attribute.Int("cleanup.rows_remaining", remaining),
+		attribute.Bool("cleanup.mismatch", v.Mismatch)))
+	if v.Mismatch {
+		uc.logger.Warn("Cleanup verification found a mismatch", append(fields,
+			zap.Int("rows_remaining", remaining),
+			zap.Int("rows_deleted", deleted),
+			zap.Int("rows_estimated", estimated),
+			zap.Bool("estimate_exact", exact))...)
+	}
+
+	return v
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"data-cleaner/internal/models/entities"
+
+	"go.uber.org/zap"
+)
+
+// Максимальный интервал между проверками окна обслуживания
+const windowPollInterval = time.Minute
+
+// waitForWindow блокируется, пока для таблицы не откроется окно обслуживания.
+// На время ожидания задача переводится в статус waiting_for_window.
+func (uc *cleanerUseCase) waitForWindow(ctx context.Context, tableName string, task *taskState) error {
+	for {
+		// Расписание перечитывается на каждой итерации, так как может измениться без перезапуска
+		schedule := uc.currentSettings().MaintenanceWindows
+		now := time.Now()
+		if schedule.IsOpen(tableName, now) {
+			return nil
+		}
+
+		task.setStatus(entities.StatusWaitingForWindow)
+
+		wait := windowPollInterval
+		if next := schedule.NextOpen(tableName, now); !next.IsZero() && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+
+		uc.logger.Debug("Waiting for maintenance window",
+			zap.String("table", tableName),
+			zap.Time("next_open", schedule.NextOpen(tableName, now)))
+
+		select {
+		case <-time.After(wait):
+			// Проверяем окно повторно
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+-- Журнал аудита операций очистки (append-only, записи связаны хешами)
+CREATE TABLE IF NOT EXISTS cleanup_audit_log (
+    seq           BIGINT PRIMARY KEY,
+    recorded_at   TIMESTAMPTZ NOT NULL,
+    actor         TEXT NOT NULL,
+    request_id    TEXT NOT NULL,
+    task_id       TEXT NOT NULL,
+    operation     TEXT NOT NULL,
+    table_name    TEXT NOT NULL,
+    before_date   TIMESTAMPTZ NOT NULL,
+    batch_size    INT NOT NULL,
+    dry_run       BOOLEAN NOT NULL,
+    outcome       TEXT NOT NULL,
+    rows_deleted  BIGINT NOT NULL,
+    error_message TEXT NOT NULL,
+    prev_hash     TEXT NOT NULL,
+    hash          TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cleanup_audit_log_table ON cleanup_audit_log (table_name, seq);
+
+-- Запрещаем изменение и удаление записей
+CREATE OR REPLACE FUNCTION cleanup_audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'cleanup_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cleanup_audit_log_no_update
+    BEFORE UPDATE OR DELETE ON cleanup_audit_log
+    FOR EACH ROW EXECUTE FUNCTION cleanup_audit_log_immutable();
+
+CREATE TRIGGER cleanup_audit_log_no_truncate
+    BEFORE TRUNCATE ON cleanup_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION cleanup_audit_log_immutable();
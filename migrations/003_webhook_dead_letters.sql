@@ -0,0 +1,13 @@
+-- Уведомления о завершении задач, которые не удалось доставить
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id         BIGSERIAL PRIMARY KEY,
+    event_id   TEXT NOT NULL,
+    task_id    TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    payload    JSONB NOT NULL,
+    attempts   INT NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_task ON webhook_dead_letters (task_id);
@@ -0,0 +1,23 @@
+-- Индексы seed-таблиц по ключу keyset-курсора пакетного удаления (created_at, id).
+-- Новый индекс строится до удаления прежнего индекса по created_at, поэтому таблица
+-- не остается без индекса по дате.
+--
+-- CREATE INDEX CONCURRENTLY не поддерживается для секционированных таблиц. Чтобы не
+-- блокировать запись в больших таблицах, индекс можно построить по секциям:
+--   CREATE INDEX idx_users_created_at_id ON ONLY users (created_at, id);
+--   CREATE INDEX CONCURRENTLY ... ON <секция> (created_at, id); -- для каждой секции
+--   ALTER INDEX idx_users_created_at_id ATTACH PARTITION ...;   -- для каждой секции
+-- и затем удалить прежний индекс.
+DO $$
+BEGIN
+    IF to_regclass('public.users') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id);
+        DROP INDEX IF EXISTS idx_users_created_at;
+    END IF;
+
+    IF to_regclass('public.products') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_products_created_at_id ON products (created_at, id);
+        DROP INDEX IF EXISTS idx_products_created_at;
+    END IF;
+END
+$$;
@@ -0,0 +1,7 @@
+-- Параметры выполнения в журнале аудита: параллелизм, таймауты пакета и итоги по секциям.
+-- Добавление колонок не вызывает триггеры, запрещающие изменение записей; у прежних
+-- записей значения по умолчанию, и их хеши не меняются.
+ALTER TABLE cleanup_audit_log
+    ADD COLUMN IF NOT EXISTS parallelism    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS batch_timeouts JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS partitions     JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,7 @@
+-- Способ очистки в журнале аудита: запрошенная стратегия, разрешение TRUNCATE и способ,
+-- которым строки были удалены фактически. У прежних записей значения по умолчанию,
+-- и их хеши не меняются.
+ALTER TABLE cleanup_audit_log
+    ADD COLUMN IF NOT EXISTS strategy       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS allow_truncate BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS method         TEXT NOT NULL DEFAULT '';
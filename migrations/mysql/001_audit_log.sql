@@ -0,0 +1,28 @@
+-- Журнал аудита операций очистки (append-only, записи связаны хешами)
+CREATE TABLE IF NOT EXISTS cleanup_audit_log (
+    seq           BIGINT PRIMARY KEY,
+    recorded_at   DATETIME(6) NOT NULL,
+    actor         VARCHAR(255) NOT NULL,
+    request_id    VARCHAR(255) NOT NULL,
+    task_id       VARCHAR(255) NOT NULL,
+    operation     VARCHAR(64) NOT NULL,
+    table_name    VARCHAR(255) NOT NULL,
+    before_date   DATETIME(6) NOT NULL,
+    batch_size    INT NOT NULL,
+    dry_run       BOOLEAN NOT NULL,
+    outcome       VARCHAR(64) NOT NULL,
+    rows_deleted  BIGINT NOT NULL,
+    error_message TEXT NOT NULL,
+    prev_hash     CHAR(64) NOT NULL,
+    hash          CHAR(64) NOT NULL,
+    INDEX idx_cleanup_audit_log_table (table_name, seq)
+) ENGINE = InnoDB;
+
+-- Запрещаем изменение и удаление записей (TRUNCATE закрывается правами доступа)
+CREATE TRIGGER cleanup_audit_log_no_update
+    BEFORE UPDATE ON cleanup_audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'cleanup_audit_log is append-only';
+
+CREATE TRIGGER cleanup_audit_log_no_delete
+    BEFORE DELETE ON cleanup_audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'cleanup_audit_log is append-only';
@@ -0,0 +1,12 @@
+-- Уведомления о завершении задач, которые не удалось доставить
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id   VARCHAR(255) NOT NULL,
+    task_id    VARCHAR(255) NOT NULL,
+    url        TEXT NOT NULL,
+    payload    JSON NOT NULL,
+    attempts   INT NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_webhook_dead_letters_task (task_id)
+) ENGINE = InnoDB;
@@ -0,0 +1,6 @@
+-- Параметры выполнения в журнале аудита: параллелизм, таймауты пакета и итоги по секциям.
+-- У прежних записей JSON-колонки пусты (NULL), и их хеши не меняются.
+ALTER TABLE cleanup_audit_log
+    ADD COLUMN parallelism    INT NOT NULL DEFAULT 0,
+    ADD COLUMN batch_timeouts JSON NULL,
+    ADD COLUMN partitions     JSON NULL;
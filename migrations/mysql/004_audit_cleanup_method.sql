@@ -0,0 +1,7 @@
+-- Способ очистки в журнале аудита: запрошенная стратегия, разрешение TRUNCATE и способ,
+-- которым строки были удалены фактически. У прежних записей значения по умолчанию,
+-- и их хеши не меняются.
+ALTER TABLE cleanup_audit_log
+    ADD COLUMN strategy       VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN allow_truncate BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN method         VARCHAR(64) NOT NULL DEFAULT '';